@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tyler-Meador/snippetbox/internal/models"
+)
+
+// maxBatchReferrers caps how many distinct referrers are kept per snippet and
+// day between flushes, so a flood of made-up referrers can't grow memory or
+// the referrers table without bound. Anything past the cap is counted as
+// otherReferrer.
+const maxBatchReferrers = 20
+
+const otherReferrer = "(other)"
+
+// statsDays is how many days of daily counts owners see.
+const statsDays = 30
+
+type viewKey struct {
+	snippetID int
+	day       time.Time
+}
+
+// viewRecorder counts snippet views in memory so serving a snippet never
+// waits on a database write. The counts are handed to the views model in
+// batches by flushViews.
+//
+// Unique viewers are tracked as salted hashes for the current UTC day only,
+// and the salt is never stored, so the hashes can't be traced back to a
+// session or IP address. It also means a restart during the day can count a
+// viewer twice.
+type viewRecorder struct {
+	mu     sync.Mutex
+	salt   []byte
+	day    time.Time
+	seen   map[[sha256.Size]byte]bool
+	counts map[viewKey]*models.ViewCount
+}
+
+func newViewRecorder() *viewRecorder {
+	salt := make([]byte, 32)
+
+	// rand.Read never returns an error.
+	rand.Read(salt)
+
+	return &viewRecorder{
+		salt:   salt,
+		seen:   map[[sha256.Size]byte]bool{},
+		counts: map[viewKey]*models.ViewCount{},
+	}
+}
+
+// Record counts a view of snippetID at now by visitor, which identifies a
+// session or client address, arriving from referrer, a host name or "".
+func (recorder *viewRecorder) Record(snippetID int, visitor string, referrer string, now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+
+	hash := sha256.New()
+	hash.Write(recorder.salt)
+	hash.Write(binary.BigEndian.AppendUint64(nil, uint64(snippetID)))
+	hash.Write([]byte(visitor))
+
+	var visitorKey [sha256.Size]byte
+	hash.Sum(visitorKey[:0])
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if !day.Equal(recorder.day) {
+		recorder.day = day
+		clear(recorder.seen)
+	}
+
+	key := viewKey{snippetID: snippetID, day: day}
+
+	count, ok := recorder.counts[key]
+	if !ok {
+		count = &models.ViewCount{SnippetID: snippetID, Day: day, Referrers: map[string]int{}}
+		recorder.counts[key] = count
+	}
+
+	count.Views++
+
+	if !recorder.seen[visitorKey] {
+		recorder.seen[visitorKey] = true
+		count.Uniques++
+	}
+
+	if _, ok := count.Referrers[referrer]; !ok && len(count.Referrers) >= maxBatchReferrers {
+		referrer = otherReferrer
+	}
+
+	count.Referrers[referrer]++
+}
+
+// drain returns the counts recorded since the last drain and starts afresh.
+// Which viewers have been seen today is kept.
+func (recorder *viewRecorder) drain() []models.ViewCount {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	counts := make([]models.ViewCount, 0, len(recorder.counts))
+
+	for _, count := range recorder.counts {
+		counts = append(counts, *count)
+	}
+
+	clear(recorder.counts)
+
+	return counts
+}
+
+// recordView counts a view of snippet by the current request. Owners looking
+// at their own snippets aren't counted.
+func (app *application) recordView(request *http.Request, snippet models.Snippet) {
+	if snippet.UserID == app.authenticatedUserID(request) {
+		return
+	}
+
+	visitor := "ip:" + clientIP(request)
+	if token := app.sessionManager.Token(request.Context()); token != "" {
+		visitor = "session:" + token
+	}
+
+	app.viewRecorder.Record(snippet.ID, visitor, referrerHost(request.Referer()), time.Now())
+}
+
+// referrerHost returns the lower-cased host of referrer, or "" if there is
+// none or it isn't an http(s) URL.
+func referrerHost(referrer string) string {
+	u, err := url.Parse(referrer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if len(host) > 255 {
+		return ""
+	}
+
+	return host
+}
+
+// runViewFlusher writes recorded views to the database once per interval
+// until ctx is cancelled. Views recorded after that are left for a final
+// flushViews once the server has stopped.
+func (app *application) runViewFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.flushViews()
+		}
+	}
+}
+
+func (app *application) flushViews() {
+	counts := app.viewRecorder.drain()
+	if len(counts) == 0 {
+		return
+	}
+
+	err := app.views.Add(counts)
+	if err != nil {
+		app.logger.Error("flushing views failed", slog.Int("snippets", len(counts)), slog.String("error", err.Error()))
+	}
+}
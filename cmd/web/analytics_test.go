@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+func TestViewRecorder(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC)
+
+	t.Run("Counts unique viewers per day", func(t *testing.T) {
+		recorder := newViewRecorder()
+
+		recorder.Record(1, "ip:10.0.0.1", "", now)
+		recorder.Record(1, "ip:10.0.0.1", "", now.Add(time.Hour))
+		recorder.Record(1, "ip:10.0.0.2", "example.com", now)
+		recorder.Record(2, "ip:10.0.0.1", "", now)
+
+		counts := recorder.drain()
+		assert.Equal(t, len(counts), 2)
+
+		for _, count := range counts {
+			switch count.SnippetID {
+			case 1:
+				assert.Equal(t, count.Views, 3)
+				assert.Equal(t, count.Uniques, 2)
+				assert.Equal(t, count.Referrers[""], 2)
+				assert.Equal(t, count.Referrers["example.com"], 1)
+			case 2:
+				assert.Equal(t, count.Views, 1)
+				assert.Equal(t, count.Uniques, 1)
+			}
+		}
+
+		assert.Equal(t, len(recorder.drain()), 0)
+	})
+
+	t.Run("Remembers viewers across drains", func(t *testing.T) {
+		recorder := newViewRecorder()
+
+		recorder.Record(1, "ip:10.0.0.1", "", now)
+		recorder.drain()
+		recorder.Record(1, "ip:10.0.0.1", "", now)
+
+		counts := recorder.drain()
+		assert.Equal(t, counts[0].Views, 1)
+		assert.Equal(t, counts[0].Uniques, 0)
+	})
+
+	t.Run("Starts a new day", func(t *testing.T) {
+		recorder := newViewRecorder()
+
+		recorder.Record(1, "ip:10.0.0.1", "", now)
+		recorder.Record(1, "ip:10.0.0.1", "", now.Add(24*time.Hour))
+
+		counts := recorder.drain()
+		assert.Equal(t, len(counts), 2)
+
+		for _, count := range counts {
+			assert.Equal(t, count.Views, 1)
+			assert.Equal(t, count.Uniques, 1)
+		}
+	})
+
+	t.Run("Caps referrers", func(t *testing.T) {
+		recorder := newViewRecorder()
+
+		for i := range maxBatchReferrers + 5 {
+			recorder.Record(1, "ip:10.0.0.1", fmt.Sprintf("%d.example.com", i), now)
+		}
+
+		recorder.Record(1, "ip:10.0.0.1", "0.example.com", now)
+
+		counts := recorder.drain()
+		assert.Equal(t, len(counts[0].Referrers), maxBatchReferrers+1)
+		assert.Equal(t, counts[0].Referrers[otherReferrer], 5)
+		assert.Equal(t, counts[0].Referrers["0.example.com"], 2)
+	})
+}
+
+func TestReferrerHost(t *testing.T) {
+	tests := []struct {
+		referrer string
+		want     string
+	}{
+		{"", ""},
+		{"https://News.Example.com/item?id=1", "news.example.com"},
+		{"http://localhost:4000/", "localhost"},
+		{"android-app://com.slack", ""},
+		{"not a url", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.referrer, func(t *testing.T) {
+			assert.Equal(t, referrerHost(tt.referrer), tt.want)
+		})
+	}
+}
+
+func TestSnippetViewStats(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	t.Run("Records views", func(t *testing.T) {
+		request, err := http.NewRequest(http.MethodGet, ts.URL+"/snippet/view/1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		request.Header.Set("Referer", "https://example.com/links")
+
+		response, err := ts.Client().Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response.Body.Close()
+
+		_, _, body := ts.get(t, "/snippet/view/1")
+		assert.StringNotContains(t, body, `<div class="stats">`)
+
+		counts := app.viewRecorder.drain()
+		assert.Equal(t, len(counts), 1)
+		assert.Equal(t, counts[0].Views, 2)
+		assert.Equal(t, counts[0].Uniques, 1)
+		assert.Equal(t, counts[0].Referrers["example.com"], 1)
+	})
+
+	ts.login(t)
+
+	t.Run("Owner sees stats but isn't counted", func(t *testing.T) {
+		_, _, body := ts.get(t, "/snippet/view/1")
+
+		assert.StringContains(t, body, `<div class="stats">`)
+		assert.StringContains(t, body, "12 views from 7 unique viewers in total.")
+		assert.StringContains(t, body, `<rect x="290" y="0" width="8" height="60">`)
+		assert.StringNotContains(t, body, `style=`)
+		assert.StringContains(t, body, "<td>Direct</td>")
+		assert.Equal(t, len(app.viewRecorder.drain()), 0)
+	})
+
+	t.Run("Hidden from others", func(t *testing.T) {
+		_, _, body := ts.get(t, "/snippet/view/3")
+
+		assert.StringNotContains(t, body, `<div class="stats">`)
+		assert.Equal(t, len(app.viewRecorder.drain()), 1)
+	})
+}
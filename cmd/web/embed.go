@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Tyler-Meador/snippetbox/internal/models"
+)
+
+// Default and smallest sizes of the iframe handed out for embedding.
+const (
+	embedWidth     = 600
+	embedHeight    = 400
+	minEmbedWidth  = 200
+	minEmbedHeight = 100
+)
+
+var originRX = regexp.MustCompile(`^https?://(\*\.)?[a-z0-9]([a-z0-9.-]*[a-z0-9])?(:\d{1,5})?$`)
+
+var errInvalidOrigin = errors.New("invalid origin")
+
+// parseOrigins splits a space or comma separated list of origins that may
+// frame embedded snippets, such as "https://wiki.example.com". Each must be a
+// scheme and host with an optional port and may start with a "*." wildcard,
+// as in a CSP source expression.
+func parseOrigins(value string) ([]string, error) {
+	var origins []string
+
+	for _, origin := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		origin = strings.TrimSuffix(strings.ToLower(origin), "/")
+
+		if !originRX.MatchString(origin) {
+			return nil, fmt.Errorf("%w: %q", errInvalidOrigin, origin)
+		}
+
+		origins = append(origins, origin)
+	}
+
+	return origins, nil
+}
+
+// baseURL returns the scheme and host the request was made to.
+func baseURL(request *http.Request) string {
+	scheme := "https"
+	if request.TLS == nil {
+		scheme = "http"
+	}
+
+	return scheme + "://" + request.Host
+}
+
+// snippetRefFromURL returns the id or slug of the snippet rawURL links to,
+// along with the lines it points at, if any. The URL must be a view or embed
+// link on this site, and the lines may be given by a lines query parameter or
+// a fragment such as "#L3-L9".
+func snippetRefFromURL(request *http.Request, rawURL string) (string, lineRange, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.EqualFold(u.Host, request.Host) {
+		return "", lineRange{}, false
+	}
+
+	lines, err := parseLineRange(u.Query().Get("lines"))
+	if err != nil {
+		return "", lineRange{}, false
+	}
+
+	if anchor, ok := parseLineAnchor(u.Fragment); ok && lines.IsZero() {
+		lines = anchor
+	}
+
+	for _, prefix := range []string{"/snippet/view/", "/snippet/embed/"} {
+		if ref, ok := strings.CutPrefix(u.Path, prefix); ok && ref != "" && !strings.Contains(ref, "/") {
+			return ref, lines, true
+		}
+	}
+
+	return "", lineRange{}, false
+}
+
+// embedCode returns the iframe markup that embeds snippet, or only lines of
+// it if they aren't the zero lineRange.
+func embedCode(request *http.Request, snippet models.Snippet, lines lineRange, width, height int) string {
+	src := fmt.Sprintf("%s/snippet/embed/%s", baseURL(request), snippet.Ref())
+	if !lines.IsZero() {
+		src += "?lines=" + lines.String()
+	}
+
+	return fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" title="%s" frameborder="0" loading="lazy"></iframe>`,
+		html.EscapeString(src), width, height, html.EscapeString(snippet.Title))
+}
+
+// oembedResponse is a rich type oEmbed response, as described at
+// https://oembed.com/.
+type oembedResponse struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
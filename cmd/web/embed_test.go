@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+func TestParseOrigins(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "Empty",
+			value: "",
+		},
+		{
+			name:  "Space and comma separated",
+			value: "https://wiki.example.com, http://localhost:8080  https://*.example.org/",
+			want:  []string{"https://wiki.example.com", "http://localhost:8080", "https://*.example.org"},
+		},
+		{
+			name:  "Lower cased",
+			value: "https://Wiki.Example.com",
+			want:  []string{"https://wiki.example.com"},
+		},
+		{
+			name:    "Path",
+			value:   "https://wiki.example.com/page",
+			wantErr: true,
+		},
+		{
+			name:    "No scheme",
+			value:   "wiki.example.com",
+			wantErr: true,
+		},
+		{
+			name:    "CSP keyword",
+			value:   "'unsafe-inline'",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origins, err := parseOrigins(tt.value)
+
+			assert.Equal(t, err != nil, tt.wantErr)
+			assert.Equal(t, len(origins), len(tt.want))
+
+			for i := range tt.want {
+				assert.Equal(t, origins[i], tt.want[i])
+			}
+		})
+	}
+}
+
+func TestSnippetEmbed(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	t.Run("Allows framing from embed origins", func(t *testing.T) {
+		code, header, body := ts.get(t, "/snippet/embed/1")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, header.Get("X-Frame-Options"), "")
+		assert.StringContains(t, header.Get("Content-Security-Policy"), "; frame-ancestors 'self' https://wiki.example.com")
+		assert.StringContains(t, body, `<body class="embed">`)
+		assert.StringContains(t, body, "An old silent pond...")
+		assert.StringNotContains(t, body, "<nav>")
+	})
+
+	t.Run("Denies framing everywhere else", func(t *testing.T) {
+		_, header, _ := ts.get(t, "/snippet/view/1")
+
+		assert.Equal(t, header.Get("X-Frame-Options"), "deny")
+		assert.StringContains(t, header.Get("Content-Security-Policy"), "; frame-ancestors 'none'")
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		code, _, body := ts.get(t, "/snippet/embed/7")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, "This snippet is password protected.")
+		assert.StringNotContains(t, body, "hunter2")
+	})
+
+	t.Run("Burn after reading", func(t *testing.T) {
+		code, _, _ := ts.get(t, "/snippet/embed/burnburnburnburnburnbu")
+
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+
+	t.Run("Private", func(t *testing.T) {
+		code, _, _ := ts.get(t, "/snippet/embed/privateprivateprivatep")
+
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+
+	t.Run("Lines", func(t *testing.T) {
+		code, _, body := ts.get(t, "/snippet/embed/10?lines=1")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, `href="/snippet/view/10?lines=1"`)
+		assert.StringContains(t, body, "golang:1.22")
+		assert.StringNotContains(t, body, "compose.yaml")
+	})
+
+	t.Run("Invalid lines", func(t *testing.T) {
+		code, _, _ := ts.get(t, "/snippet/embed/1?lines=x")
+
+		assert.Equal(t, code, http.StatusBadRequest)
+	})
+
+	t.Run("Offers embed code and oEmbed discovery", func(t *testing.T) {
+		_, _, body := ts.get(t, "/snippet/view/1")
+
+		assert.StringContains(t, body, `<link rel="alternate" type="application/json+oembed" href="`+ts.URL+`/oembed?url=`)
+		assert.StringContains(t, body, `&lt;iframe src=&#34;`+ts.URL+`/snippet/embed/1&#34;`)
+	})
+}
+
+func TestOEmbed(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		url        string
+		query      string
+		wantCode   int
+		wantWidth  int
+		wantHeight int
+		wantSrc    string
+	}{
+		{
+			name:       "View URL",
+			url:        ts.URL + "/snippet/view/1",
+			wantCode:   http.StatusOK,
+			wantWidth:  600,
+			wantHeight: 400,
+			wantSrc:    "/snippet/embed/1",
+		},
+		{
+			name:       "Lines",
+			url:        ts.URL + "/snippet/view/1?lines=3-9",
+			wantCode:   http.StatusOK,
+			wantWidth:  600,
+			wantHeight: 400,
+			wantSrc:    "/snippet/embed/1?lines=3-9",
+		},
+		{
+			name:       "Line anchor",
+			url:        ts.URL + "/snippet/view/1#L3-L9",
+			wantCode:   http.StatusOK,
+			wantWidth:  600,
+			wantHeight: 400,
+			wantSrc:    "/snippet/embed/1?lines=3-9",
+		},
+		{
+			name:       "Embed URL with max size",
+			url:        ts.URL + "/snippet/embed/unlistedunlistedunlist",
+			query:      "&maxwidth=300&maxheight=50",
+			wantCode:   http.StatusOK,
+			wantWidth:  300,
+			wantHeight: 100,
+			wantSrc:    "/snippet/embed/unlistedunlistedunlist",
+		},
+		{
+			name:     "Other site",
+			url:      "https://example.com/snippet/view/1",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Not a snippet",
+			url:      ts.URL + "/about",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Other user's unlisted by ID",
+			url:      ts.URL + "/snippet/view/5",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Burn after reading",
+			url:      ts.URL + "/snippet/view/burnburnburnburnburnbu",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Protected",
+			url:      ts.URL + "/snippet/view/7",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Invalid lines",
+			url:      ts.URL + "/snippet/view/1?lines=x",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "XML",
+			url:      ts.URL + "/snippet/view/1",
+			query:    "&format=xml",
+			wantCode: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, body := ts.get(t, "/oembed?url="+url.QueryEscape(tt.url)+tt.query)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, header.Get("Content-Type"), "application/json")
+
+			var response oembedResponse
+
+			err := json.Unmarshal([]byte(body), &response)
+			assert.NilError(t, err)
+
+			assert.Equal(t, response.Type, "rich")
+			assert.Equal(t, response.Width, tt.wantWidth)
+			assert.Equal(t, response.Height, tt.wantHeight)
+			assert.StringContains(t, response.HTML, `<iframe src="`+ts.URL+tt.wantSrc+`"`)
+		})
+	}
+}
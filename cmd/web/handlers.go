@@ -1,20 +1,84 @@
 package main
 
 import (
+	"cmp"
+	"encoding/json"
 	"errors"
 	"fmt"
-
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"path"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/Tyler-Meador/snippetbox/internal/diff"
 	"github.com/Tyler-Meador/snippetbox/internal/models"
 	"github.com/Tyler-Meador/snippetbox/internal/validator"
 )
 
 type snippetCreateForm struct {
-	Title               string `form:"title"`
+	Title               string            `form:"title"`
+	Content             string            `form:"content"`
+	Filename            string            `form:"filename"`
+	Language            string            `form:"language"`
+	Files               []snippetFileForm `form:"files"`
+	Action              string            `form:"action"`
+	Visibility          string            `form:"visibility"`
+	Password            string            `form:"password"`
+	RemovePassword      bool              `form:"removePassword"`
+	HasPassword         bool              `form:"-"`
+	BurnAfterReading    bool              `form:"burnAfterReading"`
+	Expires             string            `form:"expires"`
+	ExpiresAt           string            `form:"expiresAt"`
+	Tags                string            `form:"tags"`
+	validator.Validator `form:"-"`
+
+	expires time.Time
+}
+
+// snippetFileForm is one of the extra files on the snippet form.
+type snippetFileForm struct {
+	Name     string `form:"name"`
+	Language string `form:"language"`
+	Content  string `form:"content"`
+}
+
+type snippetUnlockForm struct {
+	Password            string `form:"password"`
+	validator.Validator `form:"-"`
+}
+
+type commentForm struct {
 	Content             string `form:"content"`
-	Expires             int    `form:"expires"`
+	ParentID            int    `form:"parent"`
+	validator.Validator `form:"-"`
+}
+
+type collectionForm struct {
+	Name                string `form:"name"`
+	validator.Validator `form:"-"`
+}
+
+// collectionAddForm adds Snippet, which may be an id, a slug or a link to the
+// snippet, to the collection with the slug Collection.
+type collectionAddForm struct {
+	Collection string `form:"collection"`
+	Snippet    string `form:"snippet"`
+}
+
+type collectionMoveForm struct {
+	Direction string `form:"direction"`
+}
+
+// compareForm holds the a and b query parameters of the compare page.
+type compareForm struct {
+	A                   string `form:"a"`
+	B                   string `form:"b"`
 	validator.Validator `form:"-"`
 }
 
@@ -25,72 +89,1121 @@ type userSignupForm struct {
 	validator.Validator `form:"-"`
 }
 
-type userLoginForm struct {
-	Email               string `form:"email"`
-	Password            string `form:"password"`
-	validator.Validator `form:"-"`
+type userLoginForm struct {
+	Email               string `form:"email"`
+	Password            string `form:"password"`
+	validator.Validator `form:"-"`
+}
+
+type changePasswordForm struct {
+	CurrentPassword     string `form:"currentPassword"`
+	NewPassword         string `form:"newPassword"`
+	ConfirmNewPassword  string `form:"confirmNewPassword"`
+	validator.Validator `form:"-"`
+}
+
+const (
+	maxTags  = 5
+	maxFiles = 10
+)
+
+// checkContent applies the checks shared by everything users write, such as
+// snippet files and comments.
+func checkContent(v *validator.Validator, key string, content string) {
+	v.CheckField(validator.NotBlank(content), key, "This field cannot be blank")
+}
+
+func (form *snippetCreateForm) validate(maxExpiry time.Duration) {
+	if form.Language == "" {
+		form.Language = "plaintext"
+	}
+
+	if form.Visibility == "" {
+		form.Visibility = models.VisibilityPublic
+	}
+
+	form.CheckField(validator.NotBlank(form.Title), "title", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Title, 100), "title", "This field cannot be more than 100 characters")
+	checkContent(&form.Validator, "content", form.Content)
+	form.CheckField(validator.PermittedValue(form.Language, languageNames()...), "language", "This field must be a supported language")
+
+	if form.Filename != "" {
+		form.CheckField(validator.MaxChars(form.Filename, 100), "filename", "This field cannot be more than 100 characters")
+		form.CheckField(validator.Matches(form.Filename, validator.FileNameRX), "filename", "File names may only contain letters, digits and . _ -")
+	}
+
+	form.validateFiles()
+	form.CheckField(validator.PermittedValue(form.Visibility, models.VisibilityPublic, models.VisibilityUnlisted, models.VisibilityPrivate), "visibility", "This field must equal public, unlisted or private")
+
+	if form.Password != "" {
+		form.CheckField(validator.MinChars(form.Password, 8), "password", "This field must be at least 8 characters")
+		form.CheckField(len(form.Password) <= 72, "password", "This field cannot be more than 72 bytes")
+	}
+
+	form.validateExpiry(maxExpiry)
+
+	tags := models.NormalizeTags(form.Tags)
+	form.CheckField(len(tags) <= maxTags, "tags", fmt.Sprintf("This field cannot have more than %d tags", maxTags))
+
+	for _, tag := range tags {
+		form.CheckField(validator.MaxChars(tag, 30), "tags", "Each tag cannot be more than 30 characters")
+		form.CheckField(validator.Matches(tag, validator.TagRX), "tags", "Tags may only contain letters, digits and . + # _ -")
+	}
+}
+
+func (form *snippetCreateForm) validateFiles() {
+	form.CheckField(len(form.Files) < maxFiles, "files", fmt.Sprintf("A snippet cannot have more than %d files", maxFiles))
+
+	names := map[string]bool{form.Filename: form.Filename != ""}
+
+	for i := range form.Files {
+		file := &form.Files[i]
+		key := fmt.Sprintf("files[%d].", i)
+
+		if file.Language == "" {
+			file.Language = "plaintext"
+		}
+
+		form.CheckField(validator.NotBlank(file.Name), key+"name", "This field cannot be blank")
+		form.CheckField(validator.MaxChars(file.Name, 100), key+"name", "This field cannot be more than 100 characters")
+		form.CheckField(validator.Matches(file.Name, validator.FileNameRX), key+"name", "File names may only contain letters, digits and . _ -")
+		form.CheckField(!names[file.Name], key+"name", "Each file must have a different name")
+		checkContent(&form.Validator, key+"content", file.Content)
+		form.CheckField(validator.PermittedValue(file.Language, languageNames()...), key+"language", "This field must be a supported language")
+
+		names[file.Name] = true
+	}
+}
+
+// fileAction applies an "add file" or "remove file" button press to the
+// form and reports whether there was one, in which case the form should be
+// shown again rather than saved.
+func (form *snippetCreateForm) fileAction() bool {
+	switch {
+	case form.Action == "add-file":
+		if len(form.Files) < maxFiles-1 {
+			form.Files = append(form.Files, snippetFileForm{Language: "plaintext"})
+		}
+		return true
+	case strings.HasPrefix(form.Action, "remove-file-"):
+		i, err := strconv.Atoi(strings.TrimPrefix(form.Action, "remove-file-"))
+		if err == nil && i >= 0 && i < len(form.Files) {
+			form.Files = slices.Delete(form.Files, i, i+1)
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// readUpload fills in the form from a file uploaded as the snippet's content,
+// if there is one. The file replaces anything typed into the content box, and
+// names the snippet and picks its language unless they have been set. A file
+// that is too big or isn't text is recorded as an error on the upload field.
+func (form *snippetCreateForm) readUpload(request *http.Request, maxSize int64) error {
+	if request.MultipartForm == nil || len(request.MultipartForm.File["upload"]) == 0 {
+		return nil
+	}
+
+	header := request.MultipartForm.File["upload"][0]
+
+	// Browsers send an empty, unnamed file when none was chosen.
+	if header.Filename == "" {
+		return nil
+	}
+
+	if header.Size > maxSize {
+		form.AddFieldError("upload", fmt.Sprintf("This file cannot be more than %s", humanBytes(maxSize)))
+		return nil
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(content) == 0:
+		form.AddFieldError("upload", "This file is empty")
+		return nil
+	case !isText(content):
+		form.AddFieldError("upload", "This file must be UTF-8 text, not binary")
+		return nil
+	}
+
+	// Some browsers send the full path of the file on Windows.
+	name := path.Base(strings.ReplaceAll(header.Filename, `\`, "/"))
+
+	form.Content = string(content)
+
+	if strings.TrimSpace(form.Title) == "" {
+		form.Title = cmp.Or(strings.TrimSuffix(name, path.Ext(name)), name)
+	}
+
+	if form.Filename == "" && validator.MaxChars(name, 100) && validator.Matches(name, validator.FileNameRX) {
+		form.Filename = name
+	}
+
+	if form.Language == "" || form.Language == "plaintext" {
+		form.Language = languageForFilename(name)
+	}
+
+	return nil
+}
+
+// isText reports whether content sniffs as text and is valid UTF-8.
+func isText(content []byte) bool {
+	return strings.HasPrefix(http.DetectContentType(content), "text/") && utf8.Valid(content)
+}
+
+// validateExpiry works out when the snippet should expire from the chosen
+// option, leaving form.expires zero if it should never expire. A non-zero
+// maxExpiry caps how far ahead that can be and rules out never expiring.
+func (form *snippetCreateForm) validateExpiry(maxExpiry time.Duration) {
+	now := time.Now().UTC()
+	field := "expires"
+
+	switch form.Expires {
+	case "1", "7", "365":
+		days, _ := strconv.Atoi(form.Expires)
+		form.expires = now.AddDate(0, 0, days)
+	case "never":
+		form.CheckField(maxExpiry == 0, "expires", fmt.Sprintf("This field cannot be more than %s from now", humanDuration(maxExpiry)))
+		return
+	case "custom":
+		field = "expiresAt"
+
+		expires, err := parseExpiry(form.ExpiresAt, now)
+		if err != nil {
+			form.AddFieldError(field, "This field must be a duration such as 2h or 30d, or a date such as 2026-12-31 18:00")
+			return
+		}
+
+		form.CheckField(expires.After(now), field, "This field must be in the future")
+		form.expires = expires
+	default:
+		form.AddFieldError(field, "This field must equal 1, 7, 365, never or custom")
+		return
+	}
+
+	if maxExpiry > 0 {
+		form.CheckField(!form.expires.After(now.Add(maxExpiry)), field, fmt.Sprintf("This field cannot be more than %s from now", humanDuration(maxExpiry)))
+	}
+}
+
+func (form *snippetCreateForm) input() models.SnippetInput {
+	var files []models.File
+
+	for _, file := range form.Files {
+		files = append(files, models.File{Name: file.Name, Language: file.Language, Content: file.Content})
+	}
+
+	return models.SnippetInput{
+		Title:            form.Title,
+		Content:          form.Content,
+		Filename:         form.Filename,
+		Files:            files,
+		Language:         form.Language,
+		Visibility:       form.Visibility,
+		Password:         form.Password,
+		RemovePassword:   form.RemovePassword,
+		BurnAfterReading: form.BurnAfterReading,
+		Expires:          form.expires,
+		Tags:             models.NormalizeTags(form.Tags),
+	}
+}
+
+func ping(response http.ResponseWriter, request *http.Request) {
+	response.Write([]byte("OK"))
+}
+
+func (app *application) home(response http.ResponseWriter, request *http.Request) {
+	page, err := app.snippets.Latest(app.readCursor(request))
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Snippets = page.Snippets
+	data.Page = page
+
+	app.render(response, request, http.StatusOK, "home.html", data)
+}
+
+func (app *application) search(response http.ResponseWriter, request *http.Request) {
+	query := strings.TrimSpace(request.URL.Query().Get("q"))
+
+	data := app.newTemplateData(request)
+	data.Query = query
+
+	if query != "" {
+		page, err := strconv.Atoi(request.URL.Query().Get("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+
+		data.Search, err = app.snippets.Search(query, page, app.pageSize)
+		if err != nil {
+			app.serverError(response, request, err)
+			return
+		}
+	}
+
+	app.render(response, request, http.StatusOK, "search.html", data)
+}
+
+func (app *application) tagView(response http.ResponseWriter, request *http.Request) {
+	tag := strings.ToLower(request.PathValue("name"))
+
+	page, err := app.snippets.Tagged(tag, app.readCursor(request))
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Tag = tag
+	data.Snippets = page.Snippets
+	data.Page = page
+
+	app.render(response, request, http.StatusOK, "tag.html", data)
+}
+
+func (app *application) snippetView(response http.ResponseWriter, request *http.Request) {
+	// Check the range first so that a mistyped link doesn't burn the snippet.
+	_, ok := app.lineRange(response, request)
+	if !ok {
+		return
+	}
+
+	snippet, ok := app.unlockedSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	snippet, ok = app.burnIfRead(response, request, snippet)
+	if !ok {
+		return
+	}
+
+	app.recordView(request, snippet)
+
+	app.renderSnippet(response, request, http.StatusOK, snippet, commentForm{})
+}
+
+// renderSnippet renders the view page for snippet, which the caller must
+// already have checked the current user may see, with form as the comment
+// form.
+func (app *application) renderSnippet(response http.ResponseWriter, request *http.Request, status int, snippet models.Snippet, form commentForm) {
+	data := app.newTemplateData(request)
+	data.Snippet = snippet
+	data.Form = form
+
+	// snippetView has already turned away malformed ranges.
+	data.Lines, _ = parseLineRange(request.URL.Query().Get("lines"))
+
+	if !snippet.BurnAfterReading && !snippet.Protected() {
+		data.EmbedCode = embedCode(request, snippet, data.Lines, embedWidth, embedHeight)
+		data.OEmbedURL = fmt.Sprintf("%s/oembed?url=%s", baseURL(request),
+			url.QueryEscape(fmt.Sprintf("%s/snippet/view/%s", baseURL(request), snippet.Ref())))
+	}
+
+	if !snippet.BurnAfterReading {
+		comments, err := app.comments.BySnippet(snippet.ID)
+		if err != nil {
+			app.serverError(response, request, err)
+			return
+		}
+
+		data.Comments = comments
+	}
+
+	if data.IsAuthenticated {
+		collections, err := app.collections.ByUser(data.AuthenticatedID)
+		if err != nil {
+			app.serverError(response, request, err)
+			return
+		}
+
+		data.Collections = collections
+
+		data.Starred, err = app.snippets.IsStarred(snippet.ID, data.AuthenticatedID)
+		if err != nil {
+			app.serverError(response, request, err)
+			return
+		}
+	}
+
+	if data.IsAuthenticated && snippet.UserID == data.AuthenticatedID {
+		stats, err := app.views.Stats(snippet.ID, statsDays)
+		if err != nil {
+			app.serverError(response, request, err)
+			return
+		}
+
+		data.ViewStats = newViewStats(stats)
+	}
+
+	app.render(response, request, status, "view.html", data)
+}
+
+func (app *application) snippetEmbed(response http.ResponseWriter, request *http.Request) {
+	lines, ok := app.lineRange(response, request)
+	if !ok {
+		return
+	}
+
+	snippet, ok := app.viewableSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	// Loading a page that embeds a burn after reading snippet mustn't
+	// destroy it.
+	if snippet.BurnAfterReading {
+		http.NotFound(response, request)
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Snippet = snippet
+	data.Locked = !app.isUnlocked(request, snippet)
+	data.Lines = lines
+
+	if !data.Locked {
+		app.recordView(request, snippet)
+	}
+
+	app.renderLayout(response, request, http.StatusOK, "embed.html", "embed", data)
+}
+
+func (app *application) oembed(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+
+	if format := query.Get("format"); format != "" && format != "json" {
+		app.clientError(response, http.StatusNotImplemented)
+		return
+	}
+
+	ref, lines, ok := snippetRefFromURL(request, query.Get("url"))
+	if !ok {
+		http.NotFound(response, request)
+		return
+	}
+
+	snippet, err := app.findSnippet(request, ref)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) || errors.Is(err, models.ErrDeleted) || errors.Is(err, models.ErrBurned) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return
+	}
+
+	if snippet.BurnAfterReading {
+		http.NotFound(response, request)
+		return
+	}
+
+	if snippet.Protected() {
+		app.clientError(response, http.StatusUnauthorized)
+		return
+	}
+
+	width, height := embedWidth, embedHeight
+
+	if maxWidth, err := strconv.Atoi(query.Get("maxwidth")); err == nil {
+		width = min(width, max(maxWidth, minEmbedWidth))
+	}
+
+	if maxHeight, err := strconv.Atoi(query.Get("maxheight")); err == nil {
+		height = min(height, max(maxHeight, minEmbedHeight))
+	}
+
+	js, err := json.Marshal(oembedResponse{
+		Version:      "1.0",
+		Type:         "rich",
+		Title:        snippet.Title,
+		AuthorName:   snippet.UserName,
+		ProviderName: "Snippetbox",
+		ProviderURL:  baseURL(request) + "/",
+		HTML:         embedCode(request, snippet, lines, width, height),
+		Width:        width,
+		Height:       height,
+	})
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.Write(js)
+}
+
+func (app *application) snippetRaw(response http.ResponseWriter, request *http.Request) {
+	lines, ok := app.lineRange(response, request)
+	if !ok {
+		return
+	}
+
+	snippet, ok := app.rawSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	writeRaw(response, sliceLines(snippet.Content, lines))
+}
+
+func (app *application) snippetRawFile(response http.ResponseWriter, request *http.Request) {
+	lines, ok := app.lineRange(response, request)
+	if !ok {
+		return
+	}
+
+	snippet, ok := app.rawSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	name := request.PathValue("file")
+
+	for _, file := range snippetFiles(snippet) {
+		if file.Name == name {
+			writeRaw(response, sliceLines(file.Content, lines))
+			return
+		}
+	}
+
+	http.NotFound(response, request)
+}
+
+func (app *application) snippetDownload(response http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.rawSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	files := snippetFiles(snippet)
+
+	if len(files) == 1 {
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": files[0].Name})
+
+		response.Header().Set("Content-Disposition", disposition)
+		writeRaw(response, snippet.Content)
+		return
+	}
+
+	archive, err := zipFiles(files, snippet.Created)
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	name := strings.TrimSuffix(downloadFilename(snippet), languageExtension(snippet.Language)) + ".zip"
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name})
+
+	response.Header().Set("Content-Type", "application/zip")
+	response.Header().Set("Content-Disposition", disposition)
+
+	archive.WriteTo(response)
+}
+
+func (app *application) snippetUnlockPost(response http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.viewableSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	path := fmt.Sprintf("/snippet/view/%s", snippet.Ref())
+
+	if app.isUnlocked(request, snippet) {
+		http.Redirect(response, request, path, http.StatusSeeOther)
+		return
+	}
+
+	var form snippetUnlockForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	snippetKey := strconv.Itoa(snippet.ID)
+	ipKey := clientIP(request)
+
+	if !app.snippetUnlocks.Allow(snippetKey) || !app.ipUnlocks.Allow(ipKey) {
+		form.AddNonFieldError("Too many incorrect attempts. Please try again later.")
+
+		data := app.newTemplateData(request)
+		data.Snippet = snippet
+		data.Form = form
+		app.render(response, request, http.StatusTooManyRequests, "unlock.html", data)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Password), "password", "This field cannot be blank")
+
+	if form.Valid() {
+		err = snippet.Authenticate(form.Password)
+		if err != nil {
+			if !errors.Is(err, models.ErrInvalidCredentials) {
+				app.serverError(response, request, err)
+				return
+			}
+
+			app.snippetUnlocks.Fail(snippetKey)
+			app.ipUnlocks.Fail(ipKey)
+
+			form.AddFieldError("password", "Password is incorrect")
+		}
+	}
+
+	if !form.Valid() {
+		data := app.newTemplateData(request)
+		data.Snippet = snippet
+		data.Form = form
+		app.render(response, request, http.StatusUnprocessableEntity, "unlock.html", data)
+		return
+	}
+
+	err = app.sessionManager.RenewToken(request.Context())
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), unlockKey(snippet.ID), string(snippet.HashedPassword))
+
+	http.Redirect(response, request, path, http.StatusSeeOther)
+}
+
+func (app *application) snippetHistory(response http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.unlockedSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	if app.burnsOnView(request, snippet) {
+		http.NotFound(response, request)
+		return
+	}
+
+	revisions, err := app.snippets.Revisions(snippet.ID)
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Snippet = snippet
+
+	for i, revision := range revisions {
+		entry := historyEntry{Revision: revision}
+		if i > 0 {
+			entry.NextID = revisions[i-1].ID
+		}
+
+		data.History = append(data.History, entry)
+	}
+
+	app.render(response, request, http.StatusOK, "history.html", data)
+}
+
+func (app *application) snippetDiff(response http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.unlockedSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	if app.burnsOnView(request, snippet) {
+		http.NotFound(response, request)
+		return
+	}
+
+	query := request.URL.Query()
+
+	fromID, err := strconv.Atoi(query.Get("from"))
+	if err != nil || fromID < 1 {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	from, err := app.snippets.GetRevision(snippet.ID, fromID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return
+	}
+
+	view := diffView{
+		FromLabel:        fmt.Sprintf("Revision %d", from.ID),
+		FromTitle:        from.Title,
+		ToLabel:          "Current version",
+		ToTitle:          snippet.Title,
+		Split:            query.Get("mode") == "split",
+		IgnoreWhitespace: query.Get("w") == "1",
+	}
+
+	fromFiles := versionFiles(from.Filename, from.Language, from.Content, from.Files)
+	toFiles := versionFiles(snippet.Filename, snippet.Language, snippet.Content, snippet.Files)
+
+	if query.Get("to") != "" {
+		toID, err := strconv.Atoi(query.Get("to"))
+		if err != nil || toID < 1 {
+			app.clientError(response, http.StatusBadRequest)
+			return
+		}
+
+		to, err := app.snippets.GetRevision(snippet.ID, toID)
+		if err != nil {
+			if errors.Is(err, models.ErrNoRecord) {
+				http.NotFound(response, request)
+			} else {
+				app.serverError(response, request, err)
+			}
+			return
+		}
+
+		view.ToLabel = fmt.Sprintf("Revision %d", to.ID)
+		view.ToTitle = to.Title
+		toFiles = versionFiles(to.Filename, to.Language, to.Content, to.Files)
+	}
+
+	fromText, toText := diffTexts(fromFiles, toFiles)
+
+	view.Lines, err = diff.Compare(fromText, toText, diff.Options{IgnoreWhitespace: view.IgnoreWhitespace})
+	if err != nil {
+		if !errors.Is(err, diff.ErrTooLarge) {
+			app.serverError(response, request, err)
+			return
+		}
+		view.TooLarge = true
+	}
+	view.Rows = diff.Split(view.Lines)
+	view.UnifiedURL, view.SplitURL = diffModeURLs(request)
+	view.WhitespaceURL = whitespaceURL(request)
+
+	data := app.newTemplateData(request)
+	data.Snippet = snippet
+	data.Diff = view
+
+	app.render(response, request, http.StatusOK, "diff.html", data)
+}
+
+// compare shows the differences between the snippets named by the a and b
+// query parameters, each an id, slug or link. Until both are given it shows
+// the form for choosing them.
+func (app *application) compare(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+
+	form := compareForm{
+		A: strings.TrimSpace(query.Get("a")),
+		B: strings.TrimSpace(query.Get("b")),
+	}
+
+	// The choices of view are kept on the form until there is a diff.
+	view := diffView{
+		Split:            query.Get("mode") == "split",
+		IgnoreWhitespace: query.Get("w") == "1",
+	}
+
+	data := app.newTemplateData(request)
+	data.Diff = view
+
+	if form.A == "" || form.B == "" {
+		data.Form = form
+		app.render(response, request, http.StatusOK, "compare.html", data)
+		return
+	}
+
+	a, err := app.comparedSnippet(request, &form, "a", form.A)
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	b, err := app.comparedSnippet(request, &form, "b", form.B)
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	data.Form = form
+
+	if !form.Valid() {
+		app.render(response, request, http.StatusUnprocessableEntity, "compare.html", data)
+		return
+	}
+
+	view.FromLabel = fmt.Sprintf("Snippet #%d", a.ID)
+	view.FromTitle = a.Title
+	view.ToLabel = fmt.Sprintf("Snippet #%d", b.ID)
+	view.ToTitle = b.Title
+	view.Lines, err = diff.Compare(a.Content, b.Content, diff.Options{IgnoreWhitespace: view.IgnoreWhitespace})
+	if err != nil {
+		if !errors.Is(err, diff.ErrTooLarge) {
+			app.serverError(response, request, err)
+			return
+		}
+		view.TooLarge = true
+	}
+	view.Rows = diff.Split(view.Lines)
+	view.UnifiedURL, view.SplitURL = diffModeURLs(request)
+	view.WhitespaceURL = whitespaceURL(request)
+
+	data.Diff = view
+
+	app.render(response, request, http.StatusOK, "compare.html", data)
+}
+
+// comparedSnippet loads the snippet ref names for the compare page. If it
+// can't be compared, an error for key is added to form instead.
+func (app *application) comparedSnippet(request *http.Request, form *compareForm, key string, ref string) (models.Snippet, error) {
+	// Accept a pasted link as well as a bare id or slug.
+	ref = path.Base(strings.TrimRight(ref, "/"))
+
+	snippet, err := app.findSnippet(request, ref)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) || errors.Is(err, models.ErrDeleted) || errors.Is(err, models.ErrBurned) {
+			form.AddFieldError(key, "That snippet couldn't be found")
+			return models.Snippet{}, nil
+		}
+
+		return models.Snippet{}, err
+	}
+
+	switch {
+	case app.burnsOnView(request, snippet):
+		form.AddFieldError(key, "Burn after reading snippets can't be compared")
+	case !app.isUnlocked(request, snippet):
+		form.AddFieldError(key, "This snippet is password protected. Open it and enter the password first")
+	}
+
+	return snippet, nil
+}
+
+func (app *application) snippetCreate(response http.ResponseWriter, request *http.Request) {
+	data := app.newTemplateData(request)
+
+	data.Form = snippetCreateForm{
+		Language:   "plaintext",
+		Visibility: models.VisibilityPublic,
+		Expires:    "365",
+	}
+
+	app.render(response, request, http.StatusOK, "create.html", data)
+}
+
+func (app *application) snippetCreatePost(response http.ResponseWriter, request *http.Request) {
+	var form snippetCreateForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	err = form.readUpload(request, app.maxUpload)
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	if form.fileAction() {
+		data := app.newTemplateData(request)
+		data.Form = form
+		app.render(response, request, http.StatusOK, "create.html", data)
+		return
+	}
+
+	form.validate(app.maxExpiry)
+
+	if !form.Valid() {
+		data := app.newTemplateData(request)
+		data.Form = form
+		app.render(response, request, http.StatusUnprocessableEntity, "create.html", data)
+		return
+	}
+
+	userID := app.authenticatedUserID(request)
+
+	id, err := app.snippets.Insert(userID, form.input())
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	if form.BurnAfterReading {
+		app.sessionManager.Put(request.Context(), "flash", "Snippet successfully created! It will be destroyed the first time someone else views it.")
+	} else {
+		app.sessionManager.Put(request.Context(), "flash", "Snippet successfully created!")
+	}
+
+	http.Redirect(response, request, fmt.Sprintf("/snippet/view/%d", id), http.StatusSeeOther)
+}
+
+func (app *application) snippetEdit(response http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.ownedSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	form := snippetCreateForm{
+		Title:       snippet.Title,
+		Content:     snippet.Content,
+		Filename:    snippet.Filename,
+		Language:    snippet.Language,
+		Visibility:  snippet.Visibility,
+		HasPassword: snippet.Protected(),
+		Expires:     "never",
+		Tags:        strings.Join(snippet.Tags, ", "),
+	}
+
+	for _, file := range snippet.Files {
+		form.Files = append(form.Files, snippetFileForm{Name: file.Name, Language: file.Language, Content: file.Content})
+	}
+
+	if !snippet.Expires.IsZero() {
+		form.Expires = "custom"
+		form.ExpiresAt = snippet.Expires.UTC().Format("2006-01-02 15:04")
+	}
+
+	data := app.newTemplateData(request)
+	data.Snippet = snippet
+	data.Form = form
+
+	app.render(response, request, http.StatusOK, "edit.html", data)
+}
+
+func (app *application) snippetEditPost(response http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.ownedSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	var form snippetCreateForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	form.HasPassword = snippet.Protected()
+
+	if form.fileAction() {
+		data := app.newTemplateData(request)
+		data.Snippet = snippet
+		data.Form = form
+		app.render(response, request, http.StatusOK, "edit.html", data)
+		return
+	}
+
+	form.validate(app.maxExpiry)
+
+	if !form.Valid() {
+		data := app.newTemplateData(request)
+		data.Snippet = snippet
+		data.Form = form
+		app.render(response, request, http.StatusUnprocessableEntity, "edit.html", data)
+		return
+	}
+
+	userID := app.authenticatedUserID(request)
+
+	err = app.snippets.Update(snippet.ID, userID, form.input())
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), "flash", "Snippet successfully updated!")
+
+	http.Redirect(response, request, fmt.Sprintf("/snippet/view/%d", snippet.ID), http.StatusSeeOther)
+}
+
+func (app *application) snippetDeletePost(response http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.ownedSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	err := app.snippets.Delete(snippet.ID)
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), "flash", "Snippet moved to the trash.")
+
+	http.Redirect(response, request, "/account/view", http.StatusSeeOther)
+}
+
+func (app *application) snippetForkPost(response http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.viewableSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	if !app.isUnlocked(request, snippet) {
+		http.Redirect(response, request, fmt.Sprintf("/snippet/view/%s", snippet.Ref()), http.StatusSeeOther)
+		return
+	}
+
+	if snippet.BurnAfterReading {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	userID := app.authenticatedUserID(request)
+
+	id, err := app.snippets.Fork(snippet.ID, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), "flash", "Snippet successfully forked!")
+
+	http.Redirect(response, request, fmt.Sprintf("/snippet/view/%d", id), http.StatusSeeOther)
+}
+
+func (app *application) snippetStarPost(response http.ResponseWriter, request *http.Request) {
+	app.setStarred(response, request, true)
+}
+
+func (app *application) snippetUnstarPost(response http.ResponseWriter, request *http.Request) {
+	app.setStarred(response, request, false)
+}
+
+// setStarred stars or unstars the snippet named by the {id} path value for
+// the current user and sends them back to it.
+func (app *application) setStarred(response http.ResponseWriter, request *http.Request, starred bool) {
+	snippet, ok := app.viewableSnippet(response, request)
+	if !ok {
+		return
+	}
+
+	if !app.isUnlocked(request, snippet) {
+		http.Redirect(response, request, fmt.Sprintf("/snippet/view/%s", snippet.Ref()), http.StatusSeeOther)
+		return
+	}
+
+	// Burn after reading snippets are gone once read, so there's nothing to
+	// come back to.
+	if snippet.BurnAfterReading {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	userID := app.authenticatedUserID(request)
+
+	var err error
+
+	if starred {
+		err = app.snippets.Star(snippet.ID, userID)
+	} else {
+		err = app.snippets.Unstar(snippet.ID, userID)
+	}
+
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return
+	}
+
+	http.Redirect(response, request, fmt.Sprintf("/snippet/view/%s", snippet.Ref()), http.StatusSeeOther)
 }
 
-type changePasswordForm struct {
-	CurrentPassword     string `form:"currentPassword"`
-	NewPassword         string `form:"newPassword"`
-	ConfirmNewPassword  string `form:"confirmNewPassword"`
-	validator.Validator `form:"-"`
-}
+func (app *application) commentCreatePost(response http.ResponseWriter, request *http.Request) {
+	snippet, ok := app.viewableSnippet(response, request)
+	if !ok {
+		return
+	}
 
-func ping(response http.ResponseWriter, request *http.Request) {
-	response.Write([]byte("OK"))
-}
+	if !app.isUnlocked(request, snippet) {
+		http.Redirect(response, request, fmt.Sprintf("/snippet/view/%s", snippet.Ref()), http.StatusSeeOther)
+		return
+	}
 
-func (app *application) home(response http.ResponseWriter, request *http.Request) {
-	snippets, err := app.snippets.Latest()
-	if err != nil {
-		app.serverError(response, request, err)
+	// Burn after reading snippets are destroyed before anyone else could
+	// read a comment on them.
+	if snippet.BurnAfterReading {
+		app.clientError(response, http.StatusBadRequest)
 		return
 	}
 
-	data := app.newTemplateData(request)
-	data.Snippets = snippets
+	var form commentForm
 
-	app.render(response, request, http.StatusOK, "home.html", data)
-}
+	err := app.decodePostForm(request, &form)
+	if err != nil || form.ParentID < 0 {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
 
-func (app *application) snippetView(response http.ResponseWriter, request *http.Request) {
-	id, err := strconv.Atoi(request.PathValue("id"))
-	if err != nil || id < 1 {
-		http.NotFound(response, request)
+	checkContent(&form.Validator, "content", form.Content)
+
+	if !form.Valid() {
+		app.renderSnippet(response, request, http.StatusUnprocessableEntity, snippet, form)
 		return
 	}
 
-	snippet, err := app.snippets.Get(id)
+	id, err := app.comments.Insert(snippet.ID, app.authenticatedUserID(request), form.ParentID, form.Content)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
-			http.NotFound(response, request)
+			app.clientError(response, http.StatusBadRequest)
 		} else {
 			app.serverError(response, request, err)
 		}
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), "flash", "Comment successfully posted!")
+
+	http.Redirect(response, request, fmt.Sprintf("/snippet/view/%s#comment-%d", snippet.Ref(), id), http.StatusSeeOther)
+}
 
+func (app *application) commentEdit(response http.ResponseWriter, request *http.Request) {
+	comment, snippet, ok := app.ownedComment(response, request)
+	if !ok {
 		return
 	}
 
 	data := app.newTemplateData(request)
 	data.Snippet = snippet
+	data.Comment = comment
+	data.Form = commentForm{Content: comment.Content}
 
-	app.render(response, request, http.StatusOK, "view.html", data)
+	app.render(response, request, http.StatusOK, "comment.html", data)
 }
 
-func (app *application) snippetCreate(response http.ResponseWriter, request *http.Request) {
-	data := app.newTemplateData(request)
-
-	data.Form = snippetCreateForm{
-		Expires: 365,
+func (app *application) commentEditPost(response http.ResponseWriter, request *http.Request) {
+	comment, snippet, ok := app.ownedComment(response, request)
+	if !ok {
+		return
 	}
 
-	app.render(response, request, http.StatusOK, "create.html", data)
-}
-
-func (app *application) snippetCreatePost(response http.ResponseWriter, request *http.Request) {
-	var form snippetCreateForm
+	var form commentForm
 
 	err := app.decodePostForm(request, &form)
 	if err != nil {
@@ -98,25 +1211,66 @@ func (app *application) snippetCreatePost(response http.ResponseWriter, request
 		return
 	}
 
-	form.CheckField(validator.NotBlank(form.Title), "title", "This field cannot be blank")
-	form.CheckField(validator.MaxChars(form.Title, 100), "title", "This field cannot be more than 100 characters")
-	form.CheckField(validator.NotBlank(form.Content), "content", "This field cannot be blank")
-	form.CheckField(validator.PermittedValue(form.Expires, 1, 7, 365), "expires", "This field must equal 1, 7 or 365")
+	checkContent(&form.Validator, "content", form.Content)
 
 	if !form.Valid() {
 		data := app.newTemplateData(request)
+		data.Snippet = snippet
+		data.Comment = comment
 		data.Form = form
-		app.render(response, request, http.StatusUnprocessableEntity, "create.html", data)
+
+		app.render(response, request, http.StatusUnprocessableEntity, "comment.html", data)
+		return
+	}
+
+	err = app.comments.Update(comment.ID, form.Content)
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), "flash", "Comment successfully updated!")
+
+	http.Redirect(response, request, fmt.Sprintf("/snippet/view/%s#comment-%d", snippet.Ref(), comment.ID), http.StatusSeeOther)
+}
+
+func (app *application) commentDeletePost(response http.ResponseWriter, request *http.Request) {
+	comment, snippet, ok := app.ownedComment(response, request)
+	if !ok {
 		return
 	}
 
-	id, err := app.snippets.Insert(form.Title, form.Content, form.Expires)
+	err := app.comments.Delete(comment.ID)
 	if err != nil {
 		app.serverError(response, request, err)
 		return
 	}
 
-	app.sessionManager.Put(request.Context(), "flash", "Snippet successfully created!")
+	app.sessionManager.Put(request.Context(), "flash", "Comment successfully deleted!")
+
+	http.Redirect(response, request, fmt.Sprintf("/snippet/view/%s#comments", snippet.Ref()), http.StatusSeeOther)
+}
+
+func (app *application) snippetRestorePost(response http.ResponseWriter, request *http.Request) {
+	id, err := strconv.Atoi(request.PathValue("id"))
+	if err != nil || id < 1 {
+		http.NotFound(response, request)
+		return
+	}
+
+	userID := app.authenticatedUserID(request)
+
+	err = app.snippets.Restore(id, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), "flash", "Snippet successfully restored!")
 
 	http.Redirect(response, request, fmt.Sprintf("/snippet/view/%d", id), http.StatusSeeOther)
 }
@@ -246,7 +1400,19 @@ func (app *application) about(response http.ResponseWriter, request *http.Reques
 }
 
 func (app *application) accountView(response http.ResponseWriter, request *http.Request) {
-	id := app.sessionManager.GetInt(request.Context(), "authenticatedUserId")
+	data, ok := app.accountData(response, request)
+	if !ok {
+		return
+	}
+
+	data.Form = collectionForm{}
+
+	app.render(response, request, http.StatusOK, "account.html", data)
+}
+
+// accountData loads everything the account page shows for the current user.
+func (app *application) accountData(response http.ResponseWriter, request *http.Request) (templateData, bool) {
+	id := app.authenticatedUserID(request)
 
 	user, err := app.users.Get(id)
 	if err != nil {
@@ -255,13 +1421,245 @@ func (app *application) accountView(response http.ResponseWriter, request *http.
 		} else {
 			app.serverError(response, request, err)
 		}
-		return
+		return templateData{}, false
+	}
+
+	page, err := app.snippets.ByUser(id, app.readCursor(request))
+	if err != nil {
+		app.serverError(response, request, err)
+		return templateData{}, false
+	}
+
+	trash, err := app.snippets.Trash(id)
+	if err != nil {
+		app.serverError(response, request, err)
+		return templateData{}, false
+	}
+
+	collections, err := app.collections.ByUser(id)
+	if err != nil {
+		app.serverError(response, request, err)
+		return templateData{}, false
+	}
+
+	for i := range collections {
+		collections[i].Snippets = app.visibleSnippets(request, collections[i].Snippets)
 	}
 
 	data := app.newTemplateData(request)
 	data.User = user
+	data.Snippets = page.Snippets
+	data.Page = page
+	data.Trash = trash
+	data.Collections = collections
 
-	app.render(response, request, http.StatusOK, "account.html", data)
+	return data, true
+}
+
+func (app *application) accountStarred(response http.ResponseWriter, request *http.Request) {
+	page, err := app.snippets.Starred(app.authenticatedUserID(request), app.readCursor(request))
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	data := app.newTemplateData(request)
+	data.Snippets = page.Snippets
+	data.Page = page
+
+	app.render(response, request, http.StatusOK, "starred.html", data)
+}
+
+func (app *application) collectionView(response http.ResponseWriter, request *http.Request) {
+	collection, err := app.collections.Get(request.PathValue("slug"))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return
+	}
+
+	collection.Snippets = app.visibleSnippets(request, collection.Snippets)
+
+	data := app.newTemplateData(request)
+	data.Collection = collection
+
+	app.render(response, request, http.StatusOK, "collection.html", data)
+}
+
+func (app *application) collectionCreatePost(response http.ResponseWriter, request *http.Request) {
+	var form collectionForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Name, 100), "name", "This field cannot be more than 100 characters long")
+
+	if !form.Valid() {
+		data, ok := app.accountData(response, request)
+		if !ok {
+			return
+		}
+
+		data.Form = form
+
+		app.render(response, request, http.StatusUnprocessableEntity, "account.html", data)
+		return
+	}
+
+	_, err = app.collections.Insert(app.authenticatedUserID(request), form.Name)
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), "flash", "Collection successfully created!")
+
+	http.Redirect(response, request, "/account/view", http.StatusSeeOther)
+}
+
+func (app *application) collectionDeletePost(response http.ResponseWriter, request *http.Request) {
+	collection, ok := app.ownedCollection(response, request, request.PathValue("slug"))
+	if !ok {
+		return
+	}
+
+	err := app.collections.Delete(collection.ID)
+	if err != nil {
+		app.serverError(response, request, err)
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), "flash", "Collection successfully deleted!")
+
+	http.Redirect(response, request, "/account/view", http.StatusSeeOther)
+}
+
+func (app *application) collectionAddPost(response http.ResponseWriter, request *http.Request) {
+	var form collectionAddForm
+
+	err := app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	collection, ok := app.ownedCollection(response, request, form.Collection)
+	if !ok {
+		return
+	}
+
+	// Accept a pasted link as well as a bare id or slug.
+	ref := path.Base(strings.TrimRight(strings.TrimSpace(form.Snippet), "/"))
+
+	snippet, err := app.findSnippet(request, ref)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) || errors.Is(err, models.ErrDeleted) || errors.Is(err, models.ErrBurned) {
+			app.sessionManager.Put(request.Context(), "flash", "That snippet couldn't be found.")
+			http.Redirect(response, request, "/account/view", http.StatusSeeOther)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return
+	}
+
+	if snippet.BurnAfterReading {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	err = app.collections.AddSnippet(collection.ID, snippet.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicateSnippet) {
+			app.sessionManager.Put(request.Context(), "flash", fmt.Sprintf("That snippet is already in %s.", collection.Name))
+			http.Redirect(response, request, "/account/view", http.StatusSeeOther)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(request.Context(), "flash", fmt.Sprintf("Added %q to %s.", snippet.Title, collection.Name))
+
+	http.Redirect(response, request, "/account/view", http.StatusSeeOther)
+}
+
+func (app *application) collectionRemovePost(response http.ResponseWriter, request *http.Request) {
+	collection, ok := app.ownedCollection(response, request, request.PathValue("slug"))
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(request.PathValue("id"))
+	if err != nil || id < 1 {
+		http.NotFound(response, request)
+		return
+	}
+
+	err = app.collections.RemoveSnippet(collection.ID, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return
+	}
+
+	http.Redirect(response, request, "/account/view", http.StatusSeeOther)
+}
+
+func (app *application) collectionMovePost(response http.ResponseWriter, request *http.Request) {
+	collection, ok := app.ownedCollection(response, request, request.PathValue("slug"))
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(request.PathValue("id"))
+	if err != nil || id < 1 {
+		http.NotFound(response, request)
+		return
+	}
+
+	var form collectionMoveForm
+
+	err = app.decodePostForm(request, &form)
+	if err != nil {
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	var offset int
+
+	switch form.Direction {
+	case "up":
+		offset = -1
+	case "down":
+		offset = 1
+	default:
+		app.clientError(response, http.StatusBadRequest)
+		return
+	}
+
+	err = app.collections.MoveSnippet(collection.ID, id, offset)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return
+	}
+
+	http.Redirect(response, request, "/account/view", http.StatusSeeOther)
 }
 
 func (app *application) accountPasswordUpdate(response http.ResponseWriter, request *http.Request) {
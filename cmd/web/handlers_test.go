@@ -1,11 +1,20 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Tyler-Meador/snippetbox/internal/assert"
+	"github.com/Tyler-Meador/snippetbox/internal/models"
+	"github.com/Tyler-Meador/snippetbox/internal/models/mocks"
 )
 
 func TestPing(t *testing.T) {
@@ -38,6 +47,29 @@ func TestSnippetView(t *testing.T) {
 			wantCode: http.StatusOK,
 			wantBody: "An old silent pond...",
 		},
+		{
+			name:     "Highlighted",
+			urlPath:  "/snippet/view/3",
+			wantCode: http.StatusOK,
+			wantBody: `<span class="line"><span class="ln" id="L1"><a class="lnlinks" href="#L1">1</a></span>`,
+		},
+		{
+			name:     "Lines",
+			urlPath:  "/snippet/view/3?lines=1",
+			wantCode: http.StatusOK,
+			wantBody: `<span class="line hl"><span class="ln" id="L1">`,
+		},
+		{
+			name:     "Invalid lines",
+			urlPath:  "/snippet/view/3?lines=0",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Shows author",
+			urlPath:  "/snippet/view/1",
+			wantCode: http.StatusOK,
+			wantBody: "By Alice",
+		},
 		{
 			name:     "Non-existant ID",
 			urlPath:  "/snippet/view/2",
@@ -63,6 +95,38 @@ func TestSnippetView(t *testing.T) {
 			urlPath:  "/snippet/view/",
 			wantCode: http.StatusNotFound,
 		},
+		{
+			name:     "Public by slug",
+			urlPath:  "/snippet/view/aaaaaaaaaaaaaaaaaaaaaa",
+			wantCode: http.StatusOK,
+			wantBody: "An old silent pond...",
+		},
+		{
+			name:     "Unlisted by slug",
+			urlPath:  "/snippet/view/unlistedunlistedunlist",
+			wantCode: http.StatusOK,
+			wantBody: "anyone with",
+		},
+		{
+			name:     "Unlisted by ID",
+			urlPath:  "/snippet/view/5",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Private by slug",
+			urlPath:  "/snippet/view/privateprivateprivatep",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Private by ID",
+			urlPath:  "/snippet/view/6",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Unknown slug",
+			urlPath:  "/snippet/view/zzzzzzzzzzzzzzzzzzzzzz",
+			wantCode: http.StatusNotFound,
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,157 +142,2113 @@ func TestSnippetView(t *testing.T) {
 	}
 }
 
-func TestUserSignup(t *testing.T) {
+func TestSnippetUnlock(t *testing.T) {
 	app := newTestApplication(t)
+
 	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	unlock := func(t *testing.T, password string) (int, http.Header, string) {
+		_, _, body := ts.get(t, "/snippet/view/7")
+		csrfToken := extractCSRFToken(t, body)
+
+		form := url.Values{}
+		form.Add("password", password)
+		form.Add("csrf_token", csrfToken)
+
+		return ts.postForm(t, "/snippet/view/7/unlock", form)
+	}
+
+	t.Run("Locked", func(t *testing.T) {
+		code, _, body := ts.get(t, "/snippet/view/7")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, "This snippet is password protected")
+		assert.StringNotContains(t, body, "hunter2")
+	})
+
+	t.Run("History locked", func(t *testing.T) {
+		_, _, body := ts.get(t, "/snippet/view/7/history")
+
+		assert.StringContains(t, body, "This snippet is password protected")
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		code, _, body := unlock(t, "wrong")
+
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, "Password is incorrect")
+	})
+
+	t.Run("Blank password", func(t *testing.T) {
+		code, _, body := unlock(t, "")
+
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.StringContains(t, body, "This field cannot be blank")
+	})
+
+	t.Run("Correct password", func(t *testing.T) {
+		code, header, _ := unlock(t, "open sesame")
+
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, header.Get("Location"), "/snippet/view/7")
+
+		_, _, body := ts.get(t, "/snippet/view/7")
+		assert.StringContains(t, body, "hunter2")
+	})
+
+	t.Run("Other snippets stay locked", func(t *testing.T) {
+		code, _, body := ts.get(t, "/snippet/view/1")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringNotContains(t, body, "password protected")
+	})
+}
+
+func TestSnippetUnlockRateLimit(t *testing.T) {
+	app := newTestApplication(t)
 
+	ts := newTestServer(t, app.routes())
 	defer ts.Close()
 
-	_, _, body := ts.get(t, "/user/signup")
-	validCSRFToken := extractCSRFToken(t, body)
+	_, _, body := ts.get(t, "/snippet/view/7")
+	csrfToken := extractCSRFToken(t, body)
 
-	const (
-		validName     = "Bob"
-		validPassword = "validPa$$word"
-		validEmail    = "bob@example.com"
-		formTag       = `<form action="/user/signup" method="POST" novalidate>`
-	)
+	post := func(password string) int {
+		form := url.Values{}
+		form.Add("password", password)
+		form.Add("csrf_token", csrfToken)
+
+		code, _, _ := ts.postForm(t, "/snippet/view/7/unlock", form)
+		return code
+	}
+
+	for range 5 {
+		assert.Equal(t, post("wrong"), http.StatusUnprocessableEntity)
+	}
+
+	assert.Equal(t, post("open sesame"), http.StatusTooManyRequests)
+}
+
+func TestSnippetBurn(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
 
 	tests := []struct {
-		name         string
-		userName     string
-		userEmail    string
-		userPassword string
-		csrfToken    string
-		wantCode     int
-		wantFormTag  string
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
 	}{
 		{
-			name:         "Valid submission",
-			userName:     validName,
-			userEmail:    validEmail,
-			userPassword: validPassword,
-			csrfToken:    validCSRFToken,
-			wantCode:     http.StatusSeeOther,
-		},
-		{
-			name:         "Invalid CSRF Token",
-			userName:     validName,
-			userEmail:    validEmail,
-			userPassword: validPassword,
-			csrfToken:    "wrongToken",
-			wantCode:     http.StatusBadRequest,
-		},
-		{
-			name:         "Empty name",
-			userName:     "",
-			userEmail:    validEmail,
-			userPassword: validPassword,
-			csrfToken:    validCSRFToken,
-			wantCode:     http.StatusUnprocessableEntity,
-			wantFormTag:  formTag,
-		},
-		{
-			name:         "Empty email",
-			userName:     validName,
-			userEmail:    "",
-			userPassword: validPassword,
-			csrfToken:    validCSRFToken,
-			wantCode:     http.StatusUnprocessableEntity,
-			wantFormTag:  formTag,
-		},
-		{
-			name:         "Empty password",
-			userName:     validName,
-			userEmail:    validEmail,
-			userPassword: "",
-			csrfToken:    validCSRFToken,
-			wantCode:     http.StatusUnprocessableEntity,
-			wantFormTag:  formTag,
+			name:     "First view",
+			urlPath:  "/snippet/view/burnburnburnburnburnbu",
+			wantCode: http.StatusOK,
+			wantBody: "This snippet has now been destroyed",
 		},
 		{
-			name:         "Invalid email",
-			userName:     validName,
-			userEmail:    "bob@example.",
-			userPassword: validPassword,
-			csrfToken:    validCSRFToken,
-			wantCode:     http.StatusUnprocessableEntity,
-			wantFormTag:  formTag,
+			name:     "History",
+			urlPath:  "/snippet/view/burnburnburnburnburnbu/history",
+			wantCode: http.StatusNotFound,
 		},
 		{
-			name:         "Short password",
-			userName:     validName,
-			userEmail:    validEmail,
-			userPassword: "pa$$",
-			csrfToken:    validCSRFToken,
-			wantCode:     http.StatusUnprocessableEntity,
-			wantFormTag:  formTag,
+			name:     "Already burned",
+			urlPath:  "/snippet/view/burnedburnedburnedburn",
+			wantCode: http.StatusGone,
+			wantBody: "This snippet has been viewed and destroyed",
 		},
 		{
-			name:         "Duplicate email",
-			userName:     validName,
-			userEmail:    "dupe@example.com",
-			userPassword: validPassword,
-			csrfToken:    validCSRFToken,
-			wantCode:     http.StatusUnprocessableEntity,
-			wantFormTag:  formTag,
+			name:     "Already burned by ID",
+			urlPath:  "/snippet/view/9",
+			wantCode: http.StatusNotFound,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			form := url.Values{}
-			form.Add("name", tt.userName)
-			form.Add("email", tt.userEmail)
-			form.Add("password", tt.userPassword)
-			form.Add("csrf_token", tt.csrfToken)
-
-			code, _, body := ts.postForm(t, "/user/signup", form)
+			code, header, body := ts.get(t, tt.urlPath)
 
 			assert.Equal(t, code, tt.wantCode)
 
-			if tt.wantFormTag != "" {
-				assert.StringContains(t, body, tt.wantFormTag)
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
 			}
 
+			if code == http.StatusOK {
+				assert.Equal(t, header.Get("Cache-Control"), "no-store")
+			}
 		})
 	}
 }
 
-func TestSnippetCreate(t *testing.T) {
+// burnCounter counts the snippets burned through it.
+type burnCounter struct {
+	*mocks.SnippetModel
+	burns int
+}
+
+func (m *burnCounter) Burn(id int) (models.Snippet, error) {
+	m.burns++
+	return m.SnippetModel.Burn(id)
+}
+
+func TestSnippetBurnHead(t *testing.T) {
 	app := newTestApplication(t)
+
+	snippets := &burnCounter{SnippetModel: &mocks.SnippetModel{}}
+	app.snippets = snippets
+
 	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	for _, urlPath := range []string{"/snippet/view/burnburnburnburnburnbu", "/snippet/raw/burnburnburnburnburnbu"} {
+		t.Run(urlPath, func(t *testing.T) {
+			snippets.burns = 0
+
+			response, err := ts.Client().Head(ts.URL + urlPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+
+			assert.Equal(t, response.StatusCode, http.StatusOK)
+			assert.Equal(t, snippets.burns, 0)
+
+			code, _, _ := ts.get(t, urlPath)
+
+			assert.Equal(t, code, http.StatusOK)
+			assert.Equal(t, snippets.burns, 1)
+		})
+	}
+}
+
+func TestSnippetRaw(t *testing.T) {
+	app := newTestApplication(t)
 
+	ts := newTestServer(t, app.routes())
 	defer ts.Close()
 
-	t.Run("Unauthenticated", func(t *testing.T) {
-		code, headers, _ := ts.get(t, "/snippet/create")
+	tests := []struct {
+		name            string
+		urlPath         string
+		wantCode        int
+		wantBody        string
+		wantDisposition string
+		wantLocation    string
+	}{
+		{
+			name:     "Raw",
+			urlPath:  "/snippet/raw/1",
+			wantCode: http.StatusOK,
+			wantBody: "An old silent pond...",
+		},
+		{
+			name:            "Download",
+			urlPath:         "/snippet/download/3",
+			wantCode:        http.StatusOK,
+			wantBody:        "Over the wintry forest, winds howl in rage...",
+			wantDisposition: "attachment; filename=over-the-wintry-forest.go",
+		},
+		{
+			name:     "Unlisted by slug",
+			urlPath:  "/snippet/raw/unlistedunlistedunlist",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Unlisted by ID",
+			urlPath:  "/snippet/raw/5",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Private",
+			urlPath:  "/snippet/download/6",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Non-existant ID",
+			urlPath:  "/snippet/raw/2",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "Locked",
+			urlPath:      "/snippet/raw/7",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/7",
+		},
+		{
+			name:     "Already burned",
+			urlPath:  "/snippet/raw/burnedburnedburnedburn",
+			wantCode: http.StatusGone,
+		},
+		{
+			name:     "Lines",
+			urlPath:  "/snippet/raw/10/compose.yaml?lines=2-3",
+			wantCode: http.StatusOK,
+			wantBody: "web:\n    build: .",
+		},
+		{
+			name:     "Invalid lines",
+			urlPath:  "/snippet/raw/1?lines=3-1",
+			wantCode: http.StatusBadRequest,
+		},
+	}
 
-		assert.Equal(t, code, http.StatusSeeOther)
-		assert.Equal(t, headers.Get("Location"), "/user/login")
-	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, body := ts.get(t, tt.urlPath)
 
-	t.Run("Authenticated", func(t *testing.T) {
-		_, _, body := ts.get(t, "/user/login")
-		validCSRFToken := extractCSRFToken(t, body)
+			assert.Equal(t, code, tt.wantCode)
 
-		const (
-			validPassword = "pa$$word"
-			validEmail    = "alice@example.com"
-			formTag       = `<form action='/snippet/create' method='POST'>`
-			wantCode      = http.StatusOK
-		)
+			if code == http.StatusOK {
+				assert.Equal(t, header.Get("Content-Type"), "text/plain; charset=utf-8")
+				assert.Equal(t, header.Get("X-Content-Type-Options"), "nosniff")
+			}
 
-		form := url.Values{}
-		form.Add("email", validEmail)
-		form.Add("password", validPassword)
-		form.Add("csrf_token", validCSRFToken)
+			if tt.wantBody != "" {
+				assert.Equal(t, body, tt.wantBody)
+			}
 
-		ts.postForm(t, "/user/login", form)
+			assert.Equal(t, header.Get("Content-Disposition"), tt.wantDisposition)
+			assert.Equal(t, header.Get("Location"), tt.wantLocation)
+		})
+	}
+}
 
-		code, _, body := ts.get(t, "/snippet/create")
+func TestCompare(t *testing.T) {
+	app := newTestApplication(t)
 
-		assert.Equal(t, code, http.StatusOK)
-		assert.StringContains(t, body, formTag)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody []string
+	}{
+		{
+			name:     "Form",
+			urlPath:  "/compare?a=1",
+			wantCode: http.StatusOK,
+			wantBody: []string{`<input type="text" name="a" value="1"`},
+		},
+		{
+			name:     "Unified",
+			urlPath:  "/compare?a=1&b=3",
+			wantCode: http.StatusOK,
+			wantBody: []string{
+				"Snippet #1 &rarr; Snippet #3",
+				"<pre>- An old silent pond...</pre>",
+				"<pre>&#43; Over the wintry forest, winds howl in rage...</pre>",
+				"Ignore whitespace</a>",
+			},
+		},
+		{
+			name:     "Split ignoring whitespace",
+			urlPath:  "/compare?a=1&b=3&mode=split&w=1",
+			wantCode: http.StatusOK,
+			wantBody: []string{`<table class="diff-split">`, "Show whitespace</a>", `<input type="hidden" name="mode" value="split">`},
+		},
+		{
+			name:     "Link and slug",
+			urlPath:  "/compare?a=" + url.QueryEscape("https://snippetbox.example.com/snippet/view/1") + "&b=unlistedunlistedunlist",
+			wantCode: http.StatusOK,
+			wantBody: []string{"Snippet #1 &rarr; Snippet #5"},
+		},
+		{
+			name:     "Not found",
+			urlPath:  "/compare?a=1&b=2",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: []string{"That snippet couldn&#39;t be found"},
+		},
+		{
+			name:     "Unlisted by ID",
+			urlPath:  "/compare?a=5&b=1",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: []string{"That snippet couldn&#39;t be found"},
+		},
+		{
+			name:     "Private",
+			urlPath:  "/compare?a=1&b=privateprivateprivatep",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: []string{"That snippet couldn&#39;t be found"},
+		},
+		{
+			name:     "Locked",
+			urlPath:  "/compare?a=7&b=1",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: []string{"This snippet is password protected."},
+		},
+		{
+			name:     "Too large",
+			urlPath:  "/compare?a=11&b=12&w=1",
+			wantCode: http.StatusOK,
+			wantBody: []string{"Snippet #11 &rarr; Snippet #12", "This diff is too large to show."},
+		},
+		{
+			name:     "Burn after reading",
+			urlPath:  "/compare?a=1&b=burnburnburnburnburnbu",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: []string{"Burn after reading snippets can&#39;t be compared"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			for _, want := range tt.wantBody {
+				assert.StringContains(t, body, want)
+			}
+
+			assert.StringNotContains(t, body, "hunter2")
+		})
+	}
+
+	t.Run("Burn after reading isn't burned", func(t *testing.T) {
+		code, _, _ := ts.get(t, "/snippet/view/burnburnburnburnburnbu")
+
+		assert.Equal(t, code, http.StatusOK)
+	})
+}
+
+func TestSnippetFork(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	t.Run("Shows fork counts", func(t *testing.T) {
+		_, _, body := ts.get(t, "/snippet/view/1")
+		assert.StringContains(t, body, "Forked 1 time")
+
+		_, _, body = ts.get(t, "/snippet/view/3")
+		assert.StringContains(t, body, `Forked from <a href="/snippet/view/1">#1</a>`)
+
+		_, _, body = ts.get(t, "/snippet/view/10")
+		assert.StringContains(t, body, `Forked from #5`)
+		assert.StringNotContains(t, body, `href="/snippet/view/5"`)
+	})
+
+	ts.login(t)
+
+	_, _, body := ts.get(t, "/snippet/view/3")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	assert.StringContains(t, body, `<form action="/snippet/fork/3" method="POST">`)
+
+	tests := []struct {
+		name         string
+		urlPath      string
+		wantCode     int
+		wantLocation string
+	}{
+		{
+			name:         "Fork",
+			urlPath:      "/snippet/fork/3",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/2",
+		},
+		{
+			name:         "Fork own private",
+			urlPath:      "/snippet/fork/6",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/2",
+		},
+		{
+			name:     "Other user's unlisted by ID",
+			urlPath:  "/snippet/fork/5",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Non-existant ID",
+			urlPath:  "/snippet/fork/2",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "Locked",
+			urlPath:      "/snippet/fork/7",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/7",
+		},
+		{
+			name:     "Burn after reading",
+			urlPath:  "/snippet/fork/burnburnburnburnburnbu",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", validCSRFToken)
+
+			code, header, _ := ts.postForm(t, tt.urlPath, form)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.Equal(t, header.Get("Location"), tt.wantLocation)
+		})
+	}
+
+	t.Run("Fork of unlocked snippet stays protected", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("password", "open sesame")
+		form.Add("csrf_token", validCSRFToken)
+
+		code, _, _ := ts.postForm(t, "/snippet/view/7/unlock", form)
+		assert.Equal(t, code, http.StatusSeeOther)
+
+		form = url.Values{}
+		form.Add("csrf_token", validCSRFToken)
+
+		code, header, _ := ts.postForm(t, "/snippet/fork/7", form)
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, header.Get("Location"), "/snippet/view/14")
+
+		_, _, body := ts.get(t, "/snippet/view/14")
+		assert.StringContains(t, body, `<span class="protected">Password protected</span>`)
+	})
+}
+
+// starCounter counts the snippets starred through it.
+type starCounter struct {
+	*mocks.SnippetModel
+	stars int
+}
+
+func (m *starCounter) Star(id int, userID int) error {
+	m.stars++
+	return m.SnippetModel.Star(id, userID)
+}
+
+func TestSnippetStar(t *testing.T) {
+	app := newTestApplication(t)
+
+	snippets := &starCounter{SnippetModel: &mocks.SnippetModel{}}
+	app.snippets = snippets
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	t.Run("Shows star counts", func(t *testing.T) {
+		_, _, body := ts.get(t, "/snippet/view/3")
+		assert.StringContains(t, body, `<span class="stars">1 star</span>`)
+		assert.StringNotContains(t, body, "/snippet/star/3")
+
+		_, _, body = ts.get(t, "/")
+		assert.StringContains(t, body, "<td>1</td>")
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, _, body := ts.get(t, "/user/login")
+
+		form := url.Values{}
+		form.Add("csrf_token", extractCSRFToken(t, body))
+
+		code, header, _ := ts.postForm(t, "/snippet/star/1", form)
+
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, header.Get("Location"), "/user/login")
+	})
+
+	ts.login(t)
+
+	_, _, body := ts.get(t, "/snippet/view/1")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	t.Run("Offers to star or unstar", func(t *testing.T) {
+		assert.StringContains(t, body, `<form action="/snippet/star/1" method="POST">`)
+
+		_, _, body := ts.get(t, "/snippet/view/3")
+		assert.StringContains(t, body, `<form action="/snippet/unstar/3" method="POST">`)
+	})
+
+	t.Run("Lists starred snippets", func(t *testing.T) {
+		code, _, body := ts.get(t, "/account/starred")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, `<a href="/snippet/view/3">Over the wintry forest</a>`)
+	})
+
+	tests := []struct {
+		name         string
+		urlPath      string
+		wantCode     int
+		wantLocation string
+	}{
+		{
+			name:         "Star",
+			urlPath:      "/snippet/star/1",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/1",
+		},
+		{
+			name:         "Unstar",
+			urlPath:      "/snippet/unstar/3",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/3",
+		},
+		{
+			name:         "Star unlisted by slug",
+			urlPath:      "/snippet/star/unlistedunlistedunlist",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/unlistedunlistedunlist",
+		},
+		{
+			name:     "Other user's unlisted by ID",
+			urlPath:  "/snippet/star/5",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Non-existant ID",
+			urlPath:  "/snippet/unstar/2",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Burn after reading",
+			urlPath:  "/snippet/star/burnburnburnburnburnbu",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", validCSRFToken)
+
+			code, header, _ := ts.postForm(t, tt.urlPath, form)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.Equal(t, header.Get("Location"), tt.wantLocation)
+		})
+	}
+
+	t.Run("Locked", func(t *testing.T) {
+		snippets.stars = 0
+
+		form := url.Values{}
+		form.Add("csrf_token", validCSRFToken)
+
+		code, header, _ := ts.postForm(t, "/snippet/star/7", form)
+
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, header.Get("Location"), "/snippet/view/7")
+		assert.Equal(t, snippets.stars, 0)
+	})
+}
+
+func TestComments(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	t.Run("Shows thread", func(t *testing.T) {
+		_, _, body := ts.get(t, "/snippet/view/1")
+
+		assert.StringContains(t, body, `<div class="comment" id="comment-1">`)
+		assert.StringContains(t, body, "It already is one.")
+		assert.StringContains(t, body, `<a href="/user/login">Log in</a> to comment.`)
+		assert.StringNotContains(t, body, "/comment/edit/1")
+	})
+
+	t.Run("Hidden for burn after reading", func(t *testing.T) {
+		_, _, body := ts.get(t, "/snippet/view/burnburnburnburnburnbu")
+
+		assert.StringNotContains(t, body, `id="comments"`)
 	})
+
+	ts.login(t)
+
+	_, _, body := ts.get(t, "/snippet/view/1")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	t.Run("Offers edit for own comments only", func(t *testing.T) {
+		assert.StringContains(t, body, `<a href="/comment/edit/1">Edit</a>`)
+		assert.StringNotContains(t, body, `<a href="/comment/edit/2">Edit</a>`)
+		assert.StringContains(t, body, `<input type="hidden" name="parent" value="2">`)
+	})
+
+	t.Run("Edit page", func(t *testing.T) {
+		code, _, body := ts.get(t, "/comment/edit/1")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, "Should this be a haiku?</textarea>")
+
+		code, _, _ = ts.get(t, "/comment/edit/2")
+		assert.Equal(t, code, http.StatusForbidden)
+	})
+
+	t.Run("Edit page hides snippets no longer viewable", func(t *testing.T) {
+		code, _, body := ts.get(t, "/comment/edit/4")
+
+		assert.Equal(t, code, http.StatusNotFound)
+		assert.StringNotContains(t, body, "Bob&#39;s private notes")
+
+		code, _, body = ts.get(t, "/comment/edit/5")
+
+		assert.Equal(t, code, http.StatusNotFound)
+		assert.StringNotContains(t, body, "Staging credentials")
+	})
+
+	tests := []struct {
+		name         string
+		urlPath      string
+		content      string
+		parent       string
+		wantCode     int
+		wantLocation string
+		wantBody     string
+	}{
+		{
+			name:         "Post",
+			urlPath:      "/snippet/view/1/comments",
+			content:      "Nice one",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/1#comment-3",
+		},
+		{
+			name:         "Reply",
+			urlPath:      "/snippet/view/1/comments",
+			content:      "Agreed",
+			parent:       "2",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/1#comment-3",
+		},
+		{
+			name:         "Post on unlisted by slug",
+			urlPath:      "/snippet/view/unlistedunlistedunlist/comments",
+			content:      "Nice one",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/unlistedunlistedunlist#comment-3",
+		},
+		{
+			name:     "Blank",
+			urlPath:  "/snippet/view/1/comments",
+			content:  "   ",
+			parent:   "2",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: `Replying to <a href="#comment-2">comment #2</a>`,
+		},
+		{
+			name:     "Reply to comment on another snippet",
+			urlPath:  "/snippet/view/3/comments",
+			content:  "Agreed",
+			parent:   "2",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Other user's unlisted by ID",
+			urlPath:  "/snippet/view/5/comments",
+			content:  "Nice one",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "Locked",
+			urlPath:      "/snippet/view/7/comments",
+			content:      "Nice one",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/7",
+		},
+		{
+			name:     "Burn after reading",
+			urlPath:  "/snippet/view/burnburnburnburnburnbu/comments",
+			content:  "Nice one",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Edit",
+			urlPath:      "/comment/edit/1",
+			content:      "Should this be a limerick?",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/1#comment-1",
+		},
+		{
+			name:     "Edit blank",
+			urlPath:  "/comment/edit/1",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This field cannot be blank",
+		},
+		{
+			name:     "Edit other user's",
+			urlPath:  "/comment/edit/2",
+			content:  "Not mine",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "Edit non-existant",
+			urlPath:  "/comment/edit/99",
+			content:  "Not there",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "Delete",
+			urlPath:      "/comment/delete/1",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/1#comments",
+		},
+		{
+			name:     "Delete other user's",
+			urlPath:  "/comment/delete/2",
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", validCSRFToken)
+			form.Add("content", tt.content)
+			if tt.parent != "" {
+				form.Add("parent", tt.parent)
+			}
+
+			code, header, body := ts.postForm(t, tt.urlPath, form)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.Equal(t, header.Get("Location"), tt.wantLocation)
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSnippetFiles(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	t.Run("View", func(t *testing.T) {
+		code, _, body := ts.get(t, "/snippet/view/10")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, `<div class="file" id="file-2">`)
+		assert.StringContains(t, body, `<a href="#file-1">compose.yaml</a>`)
+		assert.StringContains(t, body, `<a href="/snippet/raw/10/deploy.sh">Raw</a>`)
+	})
+
+	rawTests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "First file",
+			urlPath:  "/snippet/raw/10/Dockerfile",
+			wantCode: http.StatusOK,
+			wantBody: "FROM golang:1.22",
+		},
+		{
+			name:     "Extra file",
+			urlPath:  "/snippet/raw/10/deploy.sh",
+			wantCode: http.StatusOK,
+			wantBody: "docker compose up -d",
+		},
+		{
+			name:     "Unnamed first file",
+			urlPath:  "/snippet/raw/1/an-old-silent-pond.txt",
+			wantCode: http.StatusOK,
+			wantBody: "An old silent pond...",
+		},
+		{
+			name:     "Missing file",
+			urlPath:  "/snippet/raw/10/missing.txt",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range rawTests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.Equal(t, body, tt.wantBody)
+			}
+		})
+	}
+
+	t.Run("Download zip", func(t *testing.T) {
+		response, err := ts.Client().Get(ts.URL + "/snippet/download/10")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, response.StatusCode, http.StatusOK)
+		assert.Equal(t, response.Header.Get("Content-Type"), "application/zip")
+		assert.Equal(t, response.Header.Get("Content-Disposition"), "attachment; filename=deploy-bundle.zip")
+
+		archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, file := range archive.File {
+			names = append(names, file.Name)
+		}
+
+		assert.Equal(t, strings.Join(names, ","), "Dockerfile,compose.yaml,deploy.sh")
+	})
+}
+
+func TestSnippetFilesForm(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t)
+
+	_, _, body := ts.get(t, "/snippet/edit/10")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	assert.StringContains(t, body, `<input type='text' name='files[1].name' value='deploy.sh'>`)
+
+	tests := []struct {
+		name      string
+		action    string
+		files     [][2]string
+		wantCode  int
+		wantBody  string
+		wantError string
+	}{
+		{
+			name:     "Add file",
+			action:   "add-file",
+			files:    [][2]string{{"compose.yaml", "services:"}},
+			wantCode: http.StatusOK,
+			wantBody: "name='files[1].name'",
+		},
+		{
+			name:     "Remove file",
+			action:   "remove-file-0",
+			files:    [][2]string{{"compose.yaml", "services:"}, {"deploy.sh", "docker compose up -d"}},
+			wantCode: http.StatusOK,
+			wantBody: "<input type='text' name='files[0].name' value='deploy.sh'>",
+		},
+		{
+			name:     "Valid files",
+			files:    [][2]string{{"compose.yaml", "services:"}, {"deploy.sh", "docker compose up -d"}},
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:      "Duplicate names",
+			files:     [][2]string{{"deploy.sh", "one"}, {"deploy.sh", "two"}},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "Each file must have a different name",
+		},
+		{
+			name:      "Missing name",
+			files:     [][2]string{{"", "services:"}},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This field cannot be blank",
+		},
+		{
+			name:      "Path in name",
+			files:     [][2]string{{"../deploy.sh", "rm -rf /"}},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "File names may only contain letters, digits and . _ -",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("title", "Deploy bundle")
+			form.Add("content", "FROM golang:1.22")
+			form.Add("filename", "Dockerfile")
+			form.Add("expires", "7")
+			form.Add("action", tt.action)
+			form.Add("csrf_token", validCSRFToken)
+
+			for i, file := range tt.files {
+				form.Add(fmt.Sprintf("files[%d].name", i), file[0])
+				form.Add(fmt.Sprintf("files[%d].content", i), file[1])
+			}
+
+			code, _, body := ts.postForm(t, "/snippet/edit/10", form)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+
+			if tt.wantError != "" {
+				assert.StringContains(t, body, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestSnippetViewPrivate(t *testing.T) {
+	app := newTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t)
+
+	t.Run("Owner by ID", func(t *testing.T) {
+		code, _, body := ts.get(t, "/snippet/view/6")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, "only you can see this snippet")
+	})
+
+	t.Run("Owner by slug", func(t *testing.T) {
+		code, _, _ := ts.get(t, "/snippet/view/privateprivateprivatep")
+
+		assert.Equal(t, code, http.StatusOK)
+	})
+
+	t.Run("Other user's unlisted by ID", func(t *testing.T) {
+		code, _, _ := ts.get(t, "/snippet/view/5")
+
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+}
+
+func TestUserSignup(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/user/signup")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	const (
+		validName     = "Bob"
+		validPassword = "validPa$$word"
+		validEmail    = "bob@example.com"
+		formTag       = `<form action="/user/signup" method="POST" novalidate>`
+	)
+
+	tests := []struct {
+		name         string
+		userName     string
+		userEmail    string
+		userPassword string
+		csrfToken    string
+		wantCode     int
+		wantFormTag  string
+	}{
+		{
+			name:         "Valid submission",
+			userName:     validName,
+			userEmail:    validEmail,
+			userPassword: validPassword,
+			csrfToken:    validCSRFToken,
+			wantCode:     http.StatusSeeOther,
+		},
+		{
+			name:         "Invalid CSRF Token",
+			userName:     validName,
+			userEmail:    validEmail,
+			userPassword: validPassword,
+			csrfToken:    "wrongToken",
+			wantCode:     http.StatusBadRequest,
+		},
+		{
+			name:         "Empty name",
+			userName:     "",
+			userEmail:    validEmail,
+			userPassword: validPassword,
+			csrfToken:    validCSRFToken,
+			wantCode:     http.StatusUnprocessableEntity,
+			wantFormTag:  formTag,
+		},
+		{
+			name:         "Empty email",
+			userName:     validName,
+			userEmail:    "",
+			userPassword: validPassword,
+			csrfToken:    validCSRFToken,
+			wantCode:     http.StatusUnprocessableEntity,
+			wantFormTag:  formTag,
+		},
+		{
+			name:         "Empty password",
+			userName:     validName,
+			userEmail:    validEmail,
+			userPassword: "",
+			csrfToken:    validCSRFToken,
+			wantCode:     http.StatusUnprocessableEntity,
+			wantFormTag:  formTag,
+		},
+		{
+			name:         "Invalid email",
+			userName:     validName,
+			userEmail:    "bob@example.",
+			userPassword: validPassword,
+			csrfToken:    validCSRFToken,
+			wantCode:     http.StatusUnprocessableEntity,
+			wantFormTag:  formTag,
+		},
+		{
+			name:         "Short password",
+			userName:     validName,
+			userEmail:    validEmail,
+			userPassword: "pa$$",
+			csrfToken:    validCSRFToken,
+			wantCode:     http.StatusUnprocessableEntity,
+			wantFormTag:  formTag,
+		},
+		{
+			name:         "Duplicate email",
+			userName:     validName,
+			userEmail:    "dupe@example.com",
+			userPassword: validPassword,
+			csrfToken:    validCSRFToken,
+			wantCode:     http.StatusUnprocessableEntity,
+			wantFormTag:  formTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("name", tt.userName)
+			form.Add("email", tt.userEmail)
+			form.Add("password", tt.userPassword)
+			form.Add("csrf_token", tt.csrfToken)
+
+			code, _, body := ts.postForm(t, "/user/signup", form)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantFormTag != "" {
+				assert.StringContains(t, body, tt.wantFormTag)
+			}
+
+		})
+	}
+}
+
+func TestSnippetCreate(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		code, headers, _ := ts.get(t, "/snippet/create")
+
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, headers.Get("Location"), "/user/login")
+	})
+
+	t.Run("Authenticated", func(t *testing.T) {
+		_, _, body := ts.get(t, "/user/login")
+		validCSRFToken := extractCSRFToken(t, body)
+
+		const (
+			validPassword = "pa$$word"
+			validEmail    = "alice@example.com"
+			formTag       = `<form action='/snippet/create' method='POST' enctype='multipart/form-data'>`
+			wantCode      = http.StatusOK
+		)
+
+		form := url.Values{}
+		form.Add("email", validEmail)
+		form.Add("password", validPassword)
+		form.Add("csrf_token", validCSRFToken)
+
+		ts.postForm(t, "/user/login", form)
+
+		code, _, body := ts.get(t, "/snippet/create")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, formTag)
+	})
+}
+
+func TestAccountView(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		code, headers, _ := ts.get(t, "/account/view")
+
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, headers.Get("Location"), "/user/login")
+	})
+
+	t.Run("Lists own snippets", func(t *testing.T) {
+		ts.login(t)
+
+		code, _, body := ts.get(t, "/account/view")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, "My Snippets")
+		assert.StringContains(t, body, `<a href="/snippet/view/1">An old silent pond</a>`)
+	})
+
+	t.Run("Hides other users' private snippets in collections", func(t *testing.T) {
+		_, _, body := ts.get(t, "/account/view")
+
+		assert.StringContains(t, body, `<a href="/snippet/view/privateprivateprivatep">`)
+		assert.StringNotContains(t, body, "Bob&#39;s private notes")
+		assert.StringNotContains(t, body, "otherprivateotherpriva")
+	})
+}
+
+func TestCollectionView(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	t.Run("Hides private snippets from others", func(t *testing.T) {
+		code, _, body := ts.get(t, "/collection/onboardingonboardingon")
+
+		assert.Equal(t, code, http.StatusOK)
+		assert.StringContains(t, body, "<h2>Onboarding</h2>")
+		assert.StringContains(t, body, `<a href="/snippet/view/1">An old silent pond</a>`)
+		assert.StringNotContains(t, body, "A world of dew")
+	})
+
+	t.Run("Non-existent slug", func(t *testing.T) {
+		code, _, _ := ts.get(t, "/collection/missingmissingmissingmi")
+
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+
+	ts.login(t)
+
+	t.Run("Shows private snippets to their owner", func(t *testing.T) {
+		_, _, body := ts.get(t, "/collection/onboardingonboardingon")
+
+		assert.StringContains(t, body, `<a href="/snippet/view/privateprivateprivatep">A world of dew</a>`)
+	})
+
+	t.Run("Managed from the account page", func(t *testing.T) {
+		_, _, body := ts.get(t, "/account/view")
+
+		assert.StringContains(t, body, `<a href="/collection/onboardingonboardingon">Onboarding</a>`)
+		assert.StringContains(t, body, `<form action="/collection/onboardingonboardingon/move/6" method="POST">`)
+		assert.StringNotContains(t, body, "K8s recipes")
+	})
+
+	t.Run("Offered on the snippet page", func(t *testing.T) {
+		_, _, body := ts.get(t, "/snippet/view/3")
+
+		assert.StringContains(t, body, `<option value="onboardingonboardingon">Onboarding</option>`)
+	})
+}
+
+func TestCollectionPost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	ts.login(t)
+
+	_, _, body := ts.get(t, "/account/view")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name         string
+		urlPath      string
+		fields       url.Values
+		wantCode     int
+		wantLocation string
+		wantFlash    string
+	}{
+		{
+			name:         "Create",
+			urlPath:      "/collection/create",
+			fields:       url.Values{"name": {"K8s recipes"}},
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/account/view",
+			wantFlash:    "Collection successfully created!",
+		},
+		{
+			name:     "Create blank",
+			urlPath:  "/collection/create",
+			fields:   url.Values{"name": {"  "}},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "Create too long",
+			urlPath:  "/collection/create",
+			fields:   url.Values{"name": {strings.Repeat("a", 101)}},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "Add by ID",
+			urlPath:      "/collection/add",
+			fields:       url.Values{"collection": {"onboardingonboardingon"}, "snippet": {"3"}},
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/account/view",
+			wantFlash:    `Added &#34;Over the wintry forest&#34; to Onboarding.`,
+		},
+		{
+			name:         "Add by link",
+			urlPath:      "/collection/add",
+			fields:       url.Values{"collection": {"onboardingonboardingon"}, "snippet": {"https://example.com/snippet/view/unlistedunlistedunlist/"}},
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/account/view",
+			wantFlash:    "to Onboarding.",
+		},
+		{
+			name:         "Add duplicate",
+			urlPath:      "/collection/add",
+			fields:       url.Values{"collection": {"onboardingonboardingon"}, "snippet": {"1"}},
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/account/view",
+			wantFlash:    "That snippet is already in Onboarding.",
+		},
+		{
+			name:         "Add other user's unlisted by ID",
+			urlPath:      "/collection/add",
+			fields:       url.Values{"collection": {"onboardingonboardingon"}, "snippet": {"5"}},
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/account/view",
+			wantFlash:    "That snippet couldn&#39;t be found.",
+		},
+		{
+			name:     "Add burn after reading",
+			urlPath:  "/collection/add",
+			fields:   url.Values{"collection": {"onboardingonboardingon"}, "snippet": {"burnburnburnburnburnbu"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Add to other user's collection",
+			urlPath:  "/collection/add",
+			fields:   url.Values{"collection": {"k8sk8sk8sk8sk8sk8sk8sk"}, "snippet": {"1"}},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:         "Move",
+			urlPath:      "/collection/onboardingonboardingon/move/6",
+			fields:       url.Values{"direction": {"up"}},
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/account/view",
+		},
+		{
+			name:     "Move invalid direction",
+			urlPath:  "/collection/onboardingonboardingon/move/6",
+			fields:   url.Values{"direction": {"sideways"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Remove",
+			urlPath:      "/collection/onboardingonboardingon/remove/1",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/account/view",
+		},
+		{
+			name:     "Remove snippet not in collection",
+			urlPath:  "/collection/onboardingonboardingon/remove/3",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "Delete",
+			urlPath:      "/collection/onboardingonboardingon/delete",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/account/view",
+			wantFlash:    "Collection successfully deleted!",
+		},
+		{
+			name:     "Delete other user's collection",
+			urlPath:  "/collection/k8sk8sk8sk8sk8sk8sk8sk/delete",
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			for key, values := range tt.fields {
+				form[key] = values
+			}
+			form.Add("csrf_token", validCSRFToken)
+
+			code, header, _ := ts.postForm(t, tt.urlPath, form)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.Equal(t, header.Get("Location"), tt.wantLocation)
+
+			if tt.wantFlash != "" {
+				_, _, body := ts.get(t, tt.wantLocation)
+				assert.StringContains(t, body, tt.wantFlash)
+			}
+		})
+	}
+}
+
+func TestSnippetEdit(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		code, headers, _ := ts.get(t, "/snippet/edit/1")
+
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, headers.Get("Location"), "/user/login")
+	})
+
+	ts.login(t)
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Owner",
+			urlPath:  "/snippet/edit/1",
+			wantCode: http.StatusOK,
+			wantBody: `<form action='/snippet/edit/1' method='POST'>`,
+		},
+		{
+			name:     "Not owner",
+			urlPath:  "/snippet/edit/3",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "Non-existant ID",
+			urlPath:  "/snippet/edit/2",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+
+	_, _, body := ts.get(t, "/snippet/edit/1")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	postTests := []struct {
+		name     string
+		urlPath  string
+		title    string
+		wantCode int
+	}{
+		{
+			name:     "Valid submission",
+			urlPath:  "/snippet/edit/1",
+			title:    "An updated pond",
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:     "Empty title",
+			urlPath:  "/snippet/edit/1",
+			title:    "",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "Not owner",
+			urlPath:  "/snippet/edit/3",
+			title:    "Hijacked",
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range postTests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("title", tt.title)
+			form.Add("content", "An old silent pond...")
+			form.Add("expires", "7")
+			form.Add("csrf_token", validCSRFToken)
+
+			code, _, _ := ts.postForm(t, tt.urlPath, form)
+
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+}
+
+func TestSnippetHistory(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "History",
+			urlPath:  "/snippet/view/1/history",
+			wantCode: http.StatusOK,
+			wantBody: `<a href="/snippet/view/1/diff?from=1">Changes</a>`,
+		},
+		{
+			name:     "History of non-existant ID",
+			urlPath:  "/snippet/view/2/history",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Unified diff",
+			urlPath:  "/snippet/view/1/diff?from=1",
+			wantCode: http.StatusOK,
+			wantBody: `<td><pre>- An old pond...</pre></td>`,
+		},
+		{
+			name:     "Split diff",
+			urlPath:  "/snippet/view/1/diff?from=1&mode=split",
+			wantCode: http.StatusOK,
+			wantBody: `<td class="insert"><pre>An old silent pond...</pre></td>`,
+		},
+		{
+			name:     "Added file",
+			urlPath:  "/snippet/view/10/diff?from=2",
+			wantCode: http.StatusOK,
+			wantBody: `<td><pre>&#43; ==&gt; deploy.sh (bash) &lt;==</pre></td>`,
+		},
+		{
+			name:     "Missing from",
+			urlPath:  "/snippet/view/1/diff",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Non-existant revision",
+			urlPath:  "/snippet/view/1/diff?from=9",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Invalid to",
+			urlPath:  "/snippet/view/1/diff?from=1&to=foo",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSnippetDelete(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	code, _, _ := ts.get(t, "/snippet/view/4")
+	assert.Equal(t, code, http.StatusGone)
+
+	ts.login(t)
+
+	_, _, body := ts.get(t, "/snippet/view/1")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name         string
+		urlPath      string
+		wantCode     int
+		wantLocation string
+	}{
+		{
+			name:         "Delete own snippet",
+			urlPath:      "/snippet/delete/1",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/account/view",
+		},
+		{
+			name:     "Delete someone else's snippet",
+			urlPath:  "/snippet/delete/3",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "Delete trashed snippet",
+			urlPath:  "/snippet/delete/4",
+			wantCode: http.StatusGone,
+		},
+		{
+			name:         "Restore own snippet",
+			urlPath:      "/snippet/restore/4",
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "/snippet/view/4",
+		},
+		{
+			name:     "Restore snippet not in trash",
+			urlPath:  "/snippet/restore/3",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", validCSRFToken)
+
+			code, headers, _ := ts.postForm(t, tt.urlPath, form)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantLocation != "" {
+				assert.Equal(t, headers.Get("Location"), tt.wantLocation)
+			}
+		})
+	}
+
+	_, _, body = ts.get(t, "/account/view")
+	assert.StringContains(t, body, `<form action="/snippet/restore/4" method="POST">`)
+}
+
+func TestHome(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	tests := []struct {
+		name        string
+		urlPath     string
+		wantBody    []string
+		notWantBody string
+	}{
+		{
+			name:    "First page",
+			urlPath: "/",
+			wantBody: []string{
+				`<a href="/snippet/view/3">Over the wintry forest</a>`,
+				`<a href="?before=3" class="next">`,
+			},
+			notWantBody: `class="previous"`,
+		},
+		{
+			name:    "Older page",
+			urlPath: "/?before=3",
+			wantBody: []string{
+				`<a href="/snippet/view/1">An old silent pond</a>`,
+				`<a href="?after=1" class="previous">`,
+			},
+			notWantBody: `class="next"`,
+		},
+		{
+			name:    "Newer page",
+			urlPath: "/?after=1",
+			wantBody: []string{
+				`<a href="/snippet/view/3">Over the wintry forest</a>`,
+				`<a href="?before=3" class="next">`,
+			},
+			notWantBody: `class="previous"`,
+		},
+		{
+			name:    "Invalid cursor",
+			urlPath: "/?before=foo",
+			wantBody: []string{
+				`<a href="/snippet/view/3">Over the wintry forest</a>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, http.StatusOK)
+
+			for _, want := range tt.wantBody {
+				assert.StringContains(t, body, want)
+			}
+
+			if tt.notWantBody != "" {
+				assert.StringNotContains(t, body, tt.notWantBody)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantBody string
+	}{
+		{
+			name:     "Empty query",
+			urlPath:  "/search",
+			wantBody: `<form action="/search" method="GET" class="search">`,
+		},
+		{
+			name:     "Match",
+			urlPath:  "/search?q=silent",
+			wantBody: `An old <mark>silent</mark> pond...`,
+		},
+		{
+			name:     "No match",
+			urlPath:  "/search?q=frog",
+			wantBody: `No snippets matched "frog".`,
+		},
+		{
+			name:     "Protected",
+			urlPath:  "/search?q=hunter2",
+			wantBody: `No snippets matched "hunter2".`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, http.StatusOK)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestTagView(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantBody string
+	}{
+		{
+			name:     "Tag chips on snippet",
+			urlPath:  "/snippet/view/1",
+			wantBody: `<a href="/tag/haiku" class="tag">haiku</a>`,
+		},
+		{
+			name:     "Tag chip is path escaped",
+			urlPath:  "/snippet/view/3",
+			wantBody: `<a href="/tag/c%23" class="tag">c#</a>`,
+		},
+		{
+			name:     "Tagged snippets",
+			urlPath:  "/tag/haiku",
+			wantBody: `<a href="/snippet/view/1">An old silent pond</a>`,
+		},
+		{
+			name:     "Tag is case insensitive",
+			urlPath:  "/tag/HAIKU",
+			wantBody: `<a href="/snippet/view/1">An old silent pond</a>`,
+		},
+		{
+			name:     "Unused tag",
+			urlPath:  "/tag/go",
+			wantBody: "No snippets have been tagged go yet.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, http.StatusOK)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestBurnAfterReadingNotListed(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantBody string
+	}{
+		{
+			name:     "Home",
+			urlPath:  "/",
+			wantBody: `<a href="/snippet/view/3">Over the wintry forest</a>`,
+		},
+		{
+			name:     "Search",
+			urlPath:  "/search?q=silent",
+			wantBody: `An old <mark>silent</mark> pond...`,
+		},
+		{
+			name:     "Tag",
+			urlPath:  "/tag/haiku",
+			wantBody: `<a href="/snippet/view/1">An old silent pond</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, http.StatusOK)
+			assert.StringContains(t, body, tt.wantBody)
+			assert.StringNotContains(t, body, "A silent secret")
+			assert.StringNotContains(t, body, "publicburnpublicburnpu")
+		})
+	}
+}
+
+func TestSnippetCreatePost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	ts.login(t)
+
+	_, _, body := ts.get(t, "/snippet/create")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name      string
+		title     string
+		content   string
+		language  string
+		tags      string
+		expires   string
+		expiresAt string
+		wantCode  int
+		wantError string
+	}{
+		{
+			name:     "Valid submission",
+			title:    "Frog",
+			content:  "A frog jumps in",
+			tags:     "Haiku, poetry, haiku",
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:     "Never expires",
+			title:    "Frog",
+			content:  "A frog jumps in",
+			expires:  "never",
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:      "Custom duration",
+			title:     "Frog",
+			content:   "A frog jumps in",
+			expires:   "custom",
+			expiresAt: "1w2d",
+			wantCode:  http.StatusSeeOther,
+		},
+		{
+			name:      "Custom date",
+			title:     "Frog",
+			content:   "A frog jumps in",
+			expires:   "custom",
+			expiresAt: time.Now().AddDate(0, 1, 0).UTC().Format("2006-01-02 15:04"),
+			wantCode:  http.StatusSeeOther,
+		},
+		{
+			name:      "Custom date in the past",
+			title:     "Frog",
+			content:   "A frog jumps in",
+			expires:   "custom",
+			expiresAt: "2001-01-01",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This field must be in the future",
+		},
+		{
+			name:      "Custom garbage",
+			title:     "Frog",
+			content:   "A frog jumps in",
+			expires:   "custom",
+			expiresAt: "soon",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This field must be a duration such as 2h or 30d",
+		},
+		{
+			name:      "Unknown option",
+			title:     "Frog",
+			content:   "A frog jumps in",
+			expires:   "42",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This field must equal 1, 7, 365, never or custom",
+		},
+		{
+			name:     "With language",
+			title:    "Frog",
+			content:  "package frog",
+			language: "go",
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:      "Blank title",
+			title:     "",
+			content:   "A frog jumps in",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This field cannot be blank",
+		},
+		{
+			name:      "Too many tags",
+			title:     "Frog",
+			content:   "A frog jumps in",
+			tags:      "a, b, c, d, e, f",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This field cannot have more than 5 tags",
+		},
+		{
+			name:      "Invalid tag characters",
+			title:     "Frog",
+			content:   "A frog jumps in",
+			tags:      "no spaces",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "Tags may only contain letters, digits and . &#43; # _ -",
+		},
+		{
+			name:      "Unsupported language",
+			title:     "Frog",
+			content:   "A frog jumps in",
+			language:  "klingon",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This field must be a supported language",
+		},
+		{
+			name:      "Tag too long",
+			title:     "Frog",
+			content:   "A frog jumps in",
+			tags:      strings.Repeat("x", 31),
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "Each tag cannot be more than 30 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("title", tt.title)
+			form.Add("content", tt.content)
+			form.Add("expires", cmp.Or(tt.expires, "7"))
+			form.Add("expiresAt", tt.expiresAt)
+			form.Add("language", tt.language)
+			form.Add("tags", tt.tags)
+			form.Add("csrf_token", validCSRFToken)
+
+			code, _, body := ts.postForm(t, "/snippet/create", form)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantError != "" {
+				assert.StringContains(t, body, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestSnippetCreatePostUpload(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+
+	defer ts.Close()
+
+	ts.login(t)
+
+	_, _, body := ts.get(t, "/snippet/create")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name      string
+		title     string
+		content   string
+		expires   string
+		fileName  string
+		file      []byte
+		wantCode  int
+		wantBody  []string
+		wantError string
+	}{
+		{
+			name:     "Upload",
+			fileName: "hello.go",
+			file:     []byte("package main\n"),
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:     "Textarea only",
+			title:    "Frog",
+			content:  "A frog jumps in",
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:     "Detects title, file name and language",
+			expires:  "42",
+			fileName: "deploy.sh",
+			file:     []byte("#!/bin/sh\necho hi\n"),
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: []string{
+				"<input type='text' name='title' value='deploy'>",
+				"<input type='text' name='filename' value='deploy.sh'",
+				"<option value='bash' selected>Bash</option>",
+				"<textarea name='content'>#!/bin/sh\necho hi\n</textarea>",
+			},
+		},
+		{
+			name:     "Keeps a typed title",
+			title:    "Frog",
+			expires:  "42",
+			fileName: "docker-compose.yaml",
+			file:     []byte("services: {}\n"),
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: []string{
+				"<input type='text' name='title' value='Frog'>",
+				"<option value='yaml' selected>YAML</option>",
+			},
+		},
+		{
+			name:     "Replaces the textarea",
+			title:    "Frog",
+			content:  "typed",
+			expires:  "42",
+			fileName: "notes.txt",
+			file:     []byte("uploaded"),
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: []string{"<textarea name='content'>uploaded</textarea>"},
+		},
+		{
+			name:      "Binary",
+			title:     "Logo",
+			fileName:  "logo.png",
+			file:      []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This file must be UTF-8 text, not binary",
+		},
+		{
+			name:      "Not UTF-8",
+			title:     "Latin",
+			fileName:  "latin1.txt",
+			file:      []byte("caf\xe9"),
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This file must be UTF-8 text, not binary",
+		},
+		{
+			name:      "Too big",
+			fileName:  "big.txt",
+			file:      bytes.Repeat([]byte("a"), 1<<10+1),
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This file cannot be more than 1 KB",
+		},
+		{
+			name:      "Empty",
+			fileName:  "empty.txt",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This file is empty",
+		},
+		{
+			name:      "Nothing to post",
+			title:     "Frog",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantError: "This field cannot be blank",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("title", tt.title)
+			form.Add("content", tt.content)
+			form.Add("language", "plaintext")
+			form.Add("expires", cmp.Or(tt.expires, "7"))
+			form.Add("csrf_token", validCSRFToken)
+
+			code, _, body := ts.postMultipart(t, "/snippet/create", form, tt.fileName, tt.file)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			for _, want := range tt.wantBody {
+				assert.StringContains(t, body, want)
+			}
+
+			if tt.wantError != "" {
+				assert.StringContains(t, body, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestSnippetCreatePostMaxExpiry(t *testing.T) {
+	app := newTestApplication(t)
+	app.maxExpiry = 7 * 24 * time.Hour
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t)
+
+	_, _, body := ts.get(t, "/snippet/create")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	assert.StringNotContains(t, body, "value='never'")
+
+	tests := []struct {
+		name      string
+		expires   string
+		expiresAt string
+		wantCode  int
+	}{
+		{name: "Within limit", expires: "7", wantCode: http.StatusSeeOther},
+		{name: "Preset over limit", expires: "365", wantCode: http.StatusUnprocessableEntity},
+		{name: "Custom over limit", expires: "custom", expiresAt: "8d", wantCode: http.StatusUnprocessableEntity},
+		{name: "Never", expires: "never", wantCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("title", "Frog")
+			form.Add("content", "A frog jumps in")
+			form.Add("expires", tt.expires)
+			form.Add("expiresAt", tt.expiresAt)
+			form.Add("csrf_token", validCSRFToken)
+
+			code, _, body := ts.postForm(t, "/snippet/create", form)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if code == http.StatusUnprocessableEntity {
+				assert.StringContains(t, body, "This field cannot be more than 7 days from now")
+			}
+		})
+	}
 }
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"archive/zip"
 	"bytes"
+	"cmp"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"regexp"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Tyler-Meador/snippetbox/internal/models"
 	"github.com/go-playground/form/v4"
 	"github.com/justinas/nosurf"
 )
@@ -36,6 +45,12 @@ func (app *application) clientError(response http.ResponseWriter, status int) {
 }
 
 func (app *application) render(response http.ResponseWriter, request *http.Request, status int, page string, data templateData) {
+	app.renderLayout(response, request, status, page, "base", data)
+}
+
+// renderLayout is render with a layout other than base, such as embed for
+// pages that are shown inside other sites.
+func (app *application) renderLayout(response http.ResponseWriter, request *http.Request, status int, page string, layout string, data templateData) {
 	tmps, ok := app.templateCache[page]
 	if !ok {
 		err := fmt.Errorf("the template %s does not exist", page)
@@ -45,7 +60,7 @@ func (app *application) render(response http.ResponseWriter, request *http.Reque
 
 	buf := new(bytes.Buffer)
 
-	err := tmps.ExecuteTemplate(buf, "base", data)
+	err := tmps.ExecuteTemplate(buf, layout, data)
 	if err != nil {
 		app.serverError(response, request, err)
 		return
@@ -61,12 +76,25 @@ func (app *application) newTemplateData(request *http.Request) templateData {
 		CurrentYear:     time.Now().Year(),
 		Flash:           app.sessionManager.PopString(request.Context(), "flash"),
 		IsAuthenticated: app.isAuthenticated(request),
+		AuthenticatedID: app.authenticatedUserID(request),
 		CSRFToken:       nosurf.Token(request),
+		Languages:       languages,
+		MaxExpiry:       app.maxExpiry,
 	}
 }
 
+// decodePostForm decodes the fields of a urlencoded or multipart form into
+// destination. The size of the body is bounded by limitRequestBody; files in
+// a multipart form are left in request.MultipartForm.
 func (app *application) decodePostForm(request *http.Request, destination any) error {
-	err := request.ParseForm()
+	var err error
+
+	if strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data") {
+		err = request.ParseMultipartForm(app.maxUpload + maxFormOverhead)
+	} else {
+		err = request.ParseForm()
+	}
+
 	if err != nil {
 		return err
 	}
@@ -93,3 +121,516 @@ func (app *application) isAuthenticated(request *http.Request) bool {
 
 	return isAuthenticated
 }
+
+func (app *application) authenticatedUserID(request *http.Request) int {
+	if !app.isAuthenticated(request) {
+		return 0
+	}
+
+	return app.sessionManager.GetInt(request.Context(), "authenticatedUserId")
+}
+
+var slugRX = regexp.MustCompile(`^[A-Za-z0-9_-]{22}$`)
+
+// viewableSnippet loads the snippet named by the {id} path value and writes
+// an error response if the current user may not see it.
+func (app *application) viewableSnippet(response http.ResponseWriter, request *http.Request) (models.Snippet, bool) {
+	snippet, err := app.findSnippet(request, request.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNoRecord):
+			http.NotFound(response, request)
+		case errors.Is(err, models.ErrBurned):
+			app.snippetBurned(response, request, snippet)
+		case errors.Is(err, models.ErrDeleted):
+			app.clientError(response, http.StatusGone)
+		default:
+			app.serverError(response, request, err)
+		}
+
+		return models.Snippet{}, false
+	}
+
+	return snippet, true
+}
+
+// findSnippet loads the snippet ref names, which is either a numeric id or a
+// slug. Anything the current user may not see is reported as ErrNoRecord, so
+// private and unlisted snippets can't be discovered by probing ids. Burned
+// and trashed snippets the user could otherwise see are returned along with
+// ErrBurned or ErrDeleted.
+func (app *application) findSnippet(request *http.Request, ref string) (models.Snippet, error) {
+	var (
+		snippet models.Snippet
+		err     error
+		byID    bool
+	)
+
+	if id, convErr := strconv.Atoi(ref); convErr == nil {
+		if id < 1 {
+			return models.Snippet{}, models.ErrNoRecord
+		}
+
+		snippet, err = app.snippets.Get(id)
+		byID = true
+	} else if slugRX.MatchString(ref) {
+		snippet, err = app.snippets.GetBySlug(ref)
+	} else {
+		return models.Snippet{}, models.ErrNoRecord
+	}
+
+	if err != nil && !errors.Is(err, models.ErrDeleted) && !errors.Is(err, models.ErrBurned) {
+		return models.Snippet{}, err
+	}
+
+	if !app.canView(request, snippet, byID) {
+		return models.Snippet{}, models.ErrNoRecord
+	}
+
+	return snippet, err
+}
+
+func (app *application) canView(request *http.Request, snippet models.Snippet, byID bool) bool {
+	if snippet.UserID == app.authenticatedUserID(request) {
+		return true
+	}
+
+	switch snippet.Visibility {
+	case models.VisibilityPublic:
+		return true
+	case models.VisibilityUnlisted:
+		return !byID
+	default:
+		return false
+	}
+}
+
+// visibleSnippets returns the snippets the current user can view. Private
+// snippets stay private even when they have been put in a collection.
+func (app *application) visibleSnippets(request *http.Request, snippets []models.Snippet) []models.Snippet {
+	var visible []models.Snippet
+
+	for _, snippet := range snippets {
+		if app.canView(request, snippet, false) {
+			visible = append(visible, snippet)
+		}
+	}
+
+	return visible
+}
+
+func (app *application) ownedSnippet(response http.ResponseWriter, request *http.Request) (models.Snippet, bool) {
+	snippet, ok := app.viewableSnippet(response, request)
+	if !ok {
+		return models.Snippet{}, false
+	}
+
+	if snippet.UserID != app.authenticatedUserID(request) {
+		app.clientError(response, http.StatusForbidden)
+		return models.Snippet{}, false
+	}
+
+	return snippet, true
+}
+
+// ownedCollection loads the collection with the given slug and writes an
+// error response unless the current user owns it.
+func (app *application) ownedCollection(response http.ResponseWriter, request *http.Request, slug string) (models.Collection, bool) {
+	collection, err := app.collections.Get(slug)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return models.Collection{}, false
+	}
+
+	if collection.UserID != app.authenticatedUserID(request) {
+		app.clientError(response, http.StatusForbidden)
+		return models.Collection{}, false
+	}
+
+	return collection, true
+}
+
+// ownedComment loads the comment named by the {id} path value and the
+// snippet it is on, and writes an error response unless the current user
+// wrote the comment.
+func (app *application) ownedComment(response http.ResponseWriter, request *http.Request) (models.Comment, models.Snippet, bool) {
+	id, err := strconv.Atoi(request.PathValue("id"))
+	if err != nil || id < 1 {
+		http.NotFound(response, request)
+		return models.Comment{}, models.Snippet{}, false
+	}
+
+	comment, err := app.comments.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return models.Comment{}, models.Snippet{}, false
+	}
+
+	if comment.UserID != app.authenticatedUserID(request) {
+		app.clientError(response, http.StatusForbidden)
+		return models.Comment{}, models.Snippet{}, false
+	}
+
+	// Comments on snippets that have since been trashed, burned or have
+	// expired can't be changed any more.
+	snippet, err := app.snippets.Get(comment.SnippetID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) || errors.Is(err, models.ErrDeleted) || errors.Is(err, models.ErrBurned) {
+			http.NotFound(response, request)
+		} else {
+			app.serverError(response, request, err)
+		}
+		return models.Comment{}, models.Snippet{}, false
+	}
+
+	// Nor can comments on snippets that have since been made private or
+	// given a password the commenter hasn't entered. The commenter reached
+	// the snippet through its link, so it isn't treated as found by id.
+	if !app.canView(request, snippet, false) || !app.isUnlocked(request, snippet) {
+		http.NotFound(response, request)
+		return models.Comment{}, models.Snippet{}, false
+	}
+
+	return comment, snippet, true
+}
+
+// unlockedSnippet is viewableSnippet for pages that show a snippet's content.
+// If the snippet has an access password that the current session hasn't
+// entered, the unlock form is rendered instead.
+func (app *application) unlockedSnippet(response http.ResponseWriter, request *http.Request) (models.Snippet, bool) {
+	snippet, ok := app.viewableSnippet(response, request)
+	if !ok {
+		return models.Snippet{}, false
+	}
+
+	if app.isUnlocked(request, snippet) {
+		return snippet, true
+	}
+
+	data := app.newTemplateData(request)
+	data.Snippet = snippet
+	data.Form = snippetUnlockForm{}
+
+	app.render(response, request, http.StatusOK, "unlock.html", data)
+
+	return models.Snippet{}, false
+}
+
+func (app *application) isUnlocked(request *http.Request, snippet models.Snippet) bool {
+	if !snippet.Protected() || snippet.UserID == app.authenticatedUserID(request) {
+		return true
+	}
+
+	// The hash is remembered rather than a flag so that changing the
+	// password locks out everyone who unlocked the old one.
+	unlocked := app.sessionManager.GetString(request.Context(), unlockKey(snippet.ID))
+
+	return unlocked == string(snippet.HashedPassword)
+}
+
+// rawSnippet is unlockedSnippet for responses that aren't HTML pages. A
+// locked snippet redirects to its view page, where the password can be
+// entered. Burn after reading snippets are burned.
+func (app *application) rawSnippet(response http.ResponseWriter, request *http.Request) (models.Snippet, bool) {
+	snippet, ok := app.viewableSnippet(response, request)
+	if !ok {
+		return models.Snippet{}, false
+	}
+
+	if !app.isUnlocked(request, snippet) {
+		http.Redirect(response, request, fmt.Sprintf("/snippet/view/%s", snippet.Ref()), http.StatusSeeOther)
+		return models.Snippet{}, false
+	}
+
+	return app.burnIfRead(response, request, snippet)
+}
+
+// burnIfRead burns snippet if showing it to the current user destroys it,
+// returning the content as it was. Responses carrying that content must not
+// be cached.
+func (app *application) burnIfRead(response http.ResponseWriter, request *http.Request, snippet models.Snippet) (models.Snippet, bool) {
+	if !app.burnsOnView(request, snippet) {
+		return snippet, true
+	}
+
+	// HEAD responses have no body, so link unfurlers and prefetchers that
+	// send them mustn't use up the one view.
+	if request.Method == http.MethodHead {
+		response.Header().Set("Cache-Control", "no-store")
+		return snippet, true
+	}
+
+	burned, err := app.snippets.Burn(snippet.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrBurned):
+			app.snippetBurned(response, request, snippet)
+		case errors.Is(err, models.ErrNoRecord), errors.Is(err, models.ErrDeleted):
+			http.NotFound(response, request)
+		default:
+			app.serverError(response, request, err)
+		}
+		return models.Snippet{}, false
+	}
+
+	response.Header().Set("Cache-Control", "no-store")
+
+	return burned, true
+}
+
+var filenameRX = regexp.MustCompile(`[^a-z0-9]+`)
+
+// downloadFilename names a snippet's download after its title, using only
+// characters that are safe in a filename on any platform.
+func downloadFilename(snippet models.Snippet) string {
+	name := strings.Trim(filenameRX.ReplaceAllString(strings.ToLower(snippet.Title), "-"), "-")
+
+	if len(name) > 64 {
+		name = strings.TrimRight(name[:64], "-")
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("snippet-%d", snippet.ID)
+	}
+
+	return name + languageExtension(snippet.Language)
+}
+
+// snippetFiles returns every file of snippet, starting with the one held in
+// the snippet itself, which is named after the title if it has no name.
+func snippetFiles(snippet models.Snippet) []models.File {
+	first := models.File{
+		Name:     cmp.Or(snippet.Filename, downloadFilename(snippet)),
+		Language: snippet.Language,
+		Content:  snippet.Content,
+	}
+
+	return append([]models.File{first}, snippet.Files...)
+}
+
+// versionFiles returns every file of a version of a snippet, starting with
+// the one held in filename, language and content, for diffing.
+func versionFiles(filename, language, content string, files []models.File) []models.File {
+	first := models.File{Name: filename, Language: language, Content: content}
+
+	return append([]models.File{first}, files...)
+}
+
+// diffTexts returns the text to diff for two versions of a snippet. A
+// snippet with one file whose name and language haven't changed is diffed
+// as it is; otherwise each file is headed by its name and language so that
+// added, removed and renamed files show up too.
+func diffTexts(from, to []models.File) (string, string) {
+	if len(from) == 1 && len(to) == 1 && from[0].Name == to[0].Name && from[0].Language == to[0].Language {
+		return from[0].Content, to[0].Content
+	}
+
+	return filesText(from), filesText(to)
+}
+
+func filesText(files []models.File) string {
+	var builder strings.Builder
+
+	for _, file := range files {
+		fmt.Fprintf(&builder, "==> %s (%s) <==\n", cmp.Or(file.Name, "untitled"), file.Language)
+
+		builder.WriteString(file.Content)
+		if !strings.HasSuffix(file.Content, "\n") {
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String()
+}
+
+func writeRaw(response http.ResponseWriter, content string) {
+	response.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	response.Header().Set("X-Content-Type-Options", "nosniff")
+
+	io.WriteString(response, content)
+}
+
+// zipFiles builds a zip archive holding files, all dated modified.
+func zipFiles(files []models.File, modified time.Time) (*bytes.Buffer, error) {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+
+	for _, file := range files {
+		header := &zip.FileHeader{
+			Name:     file.Name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		}
+
+		entry, err := writer.CreateHeader(header)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = io.WriteString(entry, file.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// burnsOnView reports whether showing snippet to the current user destroys it.
+func (app *application) burnsOnView(request *http.Request, snippet models.Snippet) bool {
+	return snippet.BurnAfterReading && snippet.UserID != app.authenticatedUserID(request)
+}
+
+func (app *application) snippetBurned(response http.ResponseWriter, request *http.Request, snippet models.Snippet) {
+	data := app.newTemplateData(request)
+	data.Snippet = snippet
+
+	app.render(response, request, http.StatusGone, "burned.html", data)
+}
+
+func unlockKey(id int) string {
+	return fmt.Sprintf("unlockedSnippet:%d", id)
+}
+
+func clientIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+
+	return host
+}
+
+var (
+	durationRX     = regexp.MustCompile(`(?i)^(\d+[mhdw])+$`)
+	durationPartRX = regexp.MustCompile(`(?i)(\d+)([mhdw])`)
+)
+
+var durationUnits = map[string]time.Duration{
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// expiryLayouts are the absolute expiry formats accepted, all read as UTC.
+var expiryLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+var errInvalidExpiry = errors.New("invalid expiry")
+
+// parseExpiry reads a custom expiry, which is either a duration from now made
+// of minutes, hours, days and weeks such as 2h, 30d or 1w3d, or an absolute
+// date and time.
+func parseExpiry(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if durationRX.MatchString(value) {
+		var total time.Duration
+
+		for _, part := range durationPartRX.FindAllStringSubmatch(value, -1) {
+			n, err := strconv.ParseInt(part[1], 10, 64)
+			if err != nil {
+				return time.Time{}, errInvalidExpiry
+			}
+
+			unit := durationUnits[strings.ToLower(part[2])]
+
+			if n > int64((math.MaxInt64-total)/unit) {
+				return time.Time{}, errInvalidExpiry
+			}
+
+			total += time.Duration(n) * unit
+		}
+
+		return now.Add(total), nil
+	}
+
+	for _, layout := range expiryLayouts {
+		expires, err := time.ParseInLocation(layout, value, time.UTC)
+		if err == nil {
+			return expires, nil
+		}
+	}
+
+	return time.Time{}, errInvalidExpiry
+}
+
+// humanDuration formats d in whole days where it can, since that's how
+// expiry limits are usually thought about.
+func humanDuration(d time.Duration) string {
+	day := 24 * time.Hour
+
+	switch {
+	case d == day:
+		return "1 day"
+	case d%day == 0:
+		return fmt.Sprintf("%d days", d/day)
+	default:
+		return d.String()
+	}
+}
+
+// humanBytes formats a size in bytes such as 65536 as "64 KB".
+func humanBytes(n int64) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KB", n>>10)
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
+
+func diffModeURLs(request *http.Request) (string, string) {
+	query := request.URL.Query()
+
+	query.Set("mode", "unified")
+	unified := request.URL.Path + "?" + query.Encode()
+
+	query.Set("mode", "split")
+	split := request.URL.Path + "?" + query.Encode()
+
+	return unified, split
+}
+
+// whitespaceURL returns the URL of the current diff page with ignoring
+// whitespace toggled.
+func whitespaceURL(request *http.Request) string {
+	query := request.URL.Query()
+
+	if query.Get("w") == "1" {
+		query.Del("w")
+	} else {
+		query.Set("w", "1")
+	}
+
+	return request.URL.Path + "?" + query.Encode()
+}
+
+func (app *application) readCursor(request *http.Request) models.Cursor {
+	query := request.URL.Query()
+
+	cursor := models.Cursor{Size: app.pageSize}
+
+	if before, err := strconv.Atoi(query.Get("before")); err == nil && before > 0 {
+		cursor.Before = before
+	} else if after, err := strconv.Atoi(query.Get("after")); err == nil && after > 0 {
+		cursor.After = after
+	}
+
+	return cursor
+}
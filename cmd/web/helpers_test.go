@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+	"github.com/Tyler-Meador/snippetbox/internal/models"
+)
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "Hours",
+			value: "2h",
+			want:  now.Add(2 * time.Hour),
+		},
+		{
+			name:  "Days",
+			value: "30d",
+			want:  now.Add(30 * 24 * time.Hour),
+		},
+		{
+			name:  "Combined",
+			value: " 1W3d12H ",
+			want:  now.Add((10*24 + 12) * time.Hour),
+		},
+		{
+			name:  "Date",
+			value: "2024-12-31",
+			want:  time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "Date and time",
+			value: "2024-12-31 18:30",
+			want:  time.Date(2024, 12, 31, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "Datetime-local",
+			value: "2024-12-31T18:30",
+			want:  time.Date(2024, 12, 31, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "RFC 3339",
+			value: "2024-12-31T18:30:00+01:00",
+			want:  time.Date(2024, 12, 31, 17, 30, 0, 0, time.UTC),
+		},
+		{
+			name:    "Seconds",
+			value:   "30s",
+			wantErr: true,
+		},
+		{
+			name:    "Overflow",
+			value:   "99999999999999w",
+			wantErr: true,
+		},
+		{
+			name:    "Blank",
+			value:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExpiry(tt.value, now)
+
+			assert.Equal(t, err != nil, tt.wantErr)
+			assert.Equal(t, got.Equal(tt.want), true)
+		})
+	}
+}
+
+func TestDownloadFilename(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet models.Snippet
+		want    string
+	}{
+		{
+			name:    "Title and language",
+			snippet: models.Snippet{ID: 1, Title: "Deploy Script (v2)", Language: "bash"},
+			want:    "deploy-script-v2.sh",
+		},
+		{
+			name:    "Path separators",
+			snippet: models.Snippet{ID: 1, Title: "../../etc/passwd", Language: "plaintext"},
+			want:    "etc-passwd.txt",
+		},
+		{
+			name:    "No usable characters",
+			snippet: models.Snippet{ID: 7, Title: "日本語", Language: "go"},
+			want:    "snippet-7.go",
+		},
+		{
+			name:    "Unknown language",
+			snippet: models.Snippet{ID: 1, Title: "Notes", Language: "klingon"},
+			want:    "notes.txt",
+		},
+		{
+			name:    "Long title",
+			snippet: models.Snippet{ID: 1, Title: strings.Repeat("a", 70), Language: "plaintext"},
+			want:    strings.Repeat("a", 64) + ".txt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, downloadFilename(tt.snippet), tt.want)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+type language struct {
+	Name      string
+	Label     string
+	Extension string
+}
+
+// languages are the choices offered on the snippet form. Name must be a
+// chroma lexer name or alias. Extension is used to name downloads.
+var languages = []language{
+	{"plaintext", "Plain text", ".txt"},
+	{"bash", "Bash", ".sh"},
+	{"c", "C", ".c"},
+	{"cpp", "C++", ".cpp"},
+	{"csharp", "C#", ".cs"},
+	{"css", "CSS", ".css"},
+	{"diff", "Diff", ".diff"},
+	{"dockerfile", "Dockerfile", ".dockerfile"},
+	{"go", "Go", ".go"},
+	{"html", "HTML", ".html"},
+	{"ini", "INI", ".ini"},
+	{"java", "Java", ".java"},
+	{"javascript", "JavaScript", ".js"},
+	{"json", "JSON", ".json"},
+	{"kotlin", "Kotlin", ".kt"},
+	{"makefile", "Makefile", ".mk"},
+	{"markdown", "Markdown", ".md"},
+	{"php", "PHP", ".php"},
+	{"powershell", "PowerShell", ".ps1"},
+	{"python", "Python", ".py"},
+	{"ruby", "Ruby", ".rb"},
+	{"rust", "Rust", ".rs"},
+	{"sql", "SQL", ".sql"},
+	{"swift", "Swift", ".swift"},
+	{"terraform", "Terraform", ".tf"},
+	{"toml", "TOML", ".toml"},
+	{"typescript", "TypeScript", ".ts"},
+	{"yaml", "YAML", ".yaml"},
+}
+
+func languageNames() []string {
+	var names []string
+
+	for _, language := range languages {
+		names = append(names, language.Name)
+	}
+
+	return names
+}
+
+func languageLabel(name string) string {
+	for _, language := range languages {
+		if language.Name == name {
+			return language.Label
+		}
+	}
+
+	return "Plain text"
+}
+
+// The formatter only emits class attributes so the output works under the
+// Content-Security-Policy set in commonHeaders; the matching rules live in
+// ui/static/css/highlight.css.
+var (
+	highlightOptions   = []html.Option{html.WithClasses(true), html.WithLineNumbers(true), html.TabWidth(4)}
+	highlightFormatter = html.New(highlightOptions...)
+	highlightStyle     = styles.Get("github")
+)
+
+func languageExtension(name string) string {
+	for _, language := range languages {
+		if language.Name == name {
+			return language.Extension
+		}
+	}
+
+	return ".txt"
+}
+
+// languageForFilename picks the language for a file named name, falling back
+// to plain text for anything that isn't one of languages.
+func languageForFilename(name string) string {
+	lexer := lexers.Match(name)
+	if lexer == nil {
+		return "plaintext"
+	}
+
+	for _, language := range languages {
+		if candidate := lexers.Get(language.Name); candidate != nil && candidate.Config().Name == lexer.Config().Name {
+			return language.Name
+		}
+	}
+
+	return "plaintext"
+}
+
+func lexerFor(name string) chroma.Lexer {
+	lexer := lexers.Get(name)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	return chroma.Coalesce(lexer)
+}
+
+// highlight renders code as syntax highlighted HTML with line numbers.
+// Unknown languages are rendered as plain text.
+func highlight(code, language string) template.HTML {
+	return formatCode(highlightFormatter, code, language)
+}
+
+// highlightLines is highlight with each line number linking to its own line,
+// for the file at index file among a snippet's files. The first file's lines
+// have the ids L1, L2 and so on, and lines is highlighted in it; the lines of
+// later files have ids such as f1-L1 and nothing is highlighted in them.
+func highlightLines(code, language string, file int, lines lineRange) template.HTML {
+	options := append(slices.Clip(highlightOptions), html.WithLinkableLineNumbers(true, linePrefix(file)))
+
+	if file == 0 && !lines.IsZero() {
+		options = append(options, html.HighlightLines([][2]int{{lines.Start, lines.End}}))
+	}
+
+	return formatCode(html.New(options...), code, language)
+}
+
+// highlightExcerpt renders only lines of code, numbered as they are in the
+// whole of it.
+func highlightExcerpt(code, language string, lines lineRange) template.HTML {
+	if lines.IsZero() {
+		return highlight(code, language)
+	}
+
+	options := append(slices.Clip(highlightOptions), html.BaseLineNumber(lines.Start))
+
+	return formatCode(html.New(options...), sliceLines(code, lines), language)
+}
+
+func linePrefix(file int) string {
+	if file == 0 {
+		return "L"
+	}
+
+	return fmt.Sprintf("f%d-L", file)
+}
+
+func formatCode(formatter *html.Formatter, code, language string) template.HTML {
+	iterator, err := lexerFor(language).Tokenise(nil, code)
+	if err == nil {
+		var builder strings.Builder
+
+		err = formatter.Format(&builder, highlightStyle, iterator)
+		if err == nil {
+			return template.HTML(builder.String())
+		}
+	}
+
+	return template.HTML("<pre class=\"chroma\"><code>" + template.HTMLEscapeString(code) + "</code></pre>")
+}
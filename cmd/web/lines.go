@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lineRange is an inclusive range of line numbers, counting from one, as given
+// by the lines query parameter. The zero value stands for every line.
+type lineRange struct {
+	Start int
+	End   int
+}
+
+var errInvalidLines = errors.New("invalid line range")
+
+// lineAnchorRX matches the fragment of a link to a line or range of lines of
+// a snippet's first file, such as "L3" or "L3-L9".
+var lineAnchorRX = regexp.MustCompile(`^L(\d+)(?:-L(\d+))?$`)
+
+// parseLineRange parses a line number or range such as "3" or "3-9". An empty
+// value is the zero lineRange.
+func parseLineRange(value string) (lineRange, error) {
+	if value == "" {
+		return lineRange{}, nil
+	}
+
+	first, last, found := strings.Cut(value, "-")
+	if !found {
+		last = first
+	}
+
+	start, err := strconv.Atoi(first)
+	if err != nil {
+		return lineRange{}, fmt.Errorf("%w: %q", errInvalidLines, value)
+	}
+
+	end, err := strconv.Atoi(last)
+	if err != nil {
+		return lineRange{}, fmt.Errorf("%w: %q", errInvalidLines, value)
+	}
+
+	if start < 1 || end < start {
+		return lineRange{}, fmt.Errorf("%w: %q", errInvalidLines, value)
+	}
+
+	return lineRange{Start: start, End: end}, nil
+}
+
+// parseLineAnchor parses a fragment such as "L3-L9". It reports false for
+// fragments that don't name lines.
+func parseLineAnchor(fragment string) (lineRange, bool) {
+	matches := lineAnchorRX.FindStringSubmatch(fragment)
+	if matches == nil {
+		return lineRange{}, false
+	}
+
+	value := matches[1]
+	if matches[2] != "" {
+		value += "-" + matches[2]
+	}
+
+	lines, err := parseLineRange(value)
+	if err != nil {
+		return lineRange{}, false
+	}
+
+	return lines, true
+}
+
+func (lines lineRange) IsZero() bool {
+	return lines.Start == 0
+}
+
+// String formats lines the way parseLineRange reads them, or as "" for the
+// zero lineRange.
+func (lines lineRange) String() string {
+	switch {
+	case lines.IsZero():
+		return ""
+	case lines.Start == lines.End:
+		return strconv.Itoa(lines.Start)
+	default:
+		return fmt.Sprintf("%d-%d", lines.Start, lines.End)
+	}
+}
+
+// sliceLines returns the lines of content in lines, keeping their line
+// endings. Any part of the range past the end of content is ignored.
+func sliceLines(content string, lines lineRange) string {
+	if lines.IsZero() {
+		return content
+	}
+
+	split := strings.SplitAfter(content, "\n")
+	if split[len(split)-1] == "" {
+		split = split[:len(split)-1]
+	}
+
+	start := min(lines.Start, len(split)+1) - 1
+	end := min(lines.End, len(split))
+
+	return strings.Join(split[start:end], "")
+}
+
+// lineRange returns the range given by the request's lines query parameter,
+// sending a 400 response if it is malformed.
+func (app *application) lineRange(response http.ResponseWriter, request *http.Request) (lineRange, bool) {
+	lines, err := parseLineRange(request.URL.Query().Get("lines"))
+	if err != nil {
+		app.clientError(response, http.StatusBadRequest)
+		return lineRange{}, false
+	}
+
+	return lines, true
+}
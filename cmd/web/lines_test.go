@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    lineRange
+		wantErr error
+	}{
+		{
+			name:  "Empty",
+			value: "",
+			want:  lineRange{},
+		},
+		{
+			name:  "Line",
+			value: "12",
+			want:  lineRange{Start: 12, End: 12},
+		},
+		{
+			name:  "Range",
+			value: "3-9",
+			want:  lineRange{Start: 3, End: 9},
+		},
+		{
+			name:    "Backwards",
+			value:   "9-3",
+			wantErr: errInvalidLines,
+		},
+		{
+			name:    "Zero",
+			value:   "0-3",
+			wantErr: errInvalidLines,
+		},
+		{
+			name:    "Open ended",
+			value:   "3-",
+			wantErr: errInvalidLines,
+		},
+		{
+			name:    "Not a number",
+			value:   "L3",
+			wantErr: errInvalidLines,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := parseLineRange(tt.value)
+
+			assert.Equal(t, errors.Is(err, tt.wantErr), true)
+			assert.Equal(t, lines, tt.want)
+		})
+	}
+}
+
+func TestParseLineAnchor(t *testing.T) {
+	tests := []struct {
+		fragment string
+		want     lineRange
+		wantOK   bool
+	}{
+		{fragment: "L3", want: lineRange{Start: 3, End: 3}, wantOK: true},
+		{fragment: "L3-L9", want: lineRange{Start: 3, End: 9}, wantOK: true},
+		{fragment: "L9-L3"},
+		{fragment: "f1-L3"},
+		{fragment: "comments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fragment, func(t *testing.T) {
+			lines, ok := parseLineAnchor(tt.fragment)
+
+			assert.Equal(t, ok, tt.wantOK)
+			assert.Equal(t, lines, tt.want)
+		})
+	}
+}
+
+func TestSliceLines(t *testing.T) {
+	content := "one\ntwo\nthree\n"
+
+	tests := []struct {
+		name  string
+		lines lineRange
+		want  string
+	}{
+		{
+			name:  "Every line",
+			lines: lineRange{},
+			want:  content,
+		},
+		{
+			name:  "One line",
+			lines: lineRange{Start: 2, End: 2},
+			want:  "two\n",
+		},
+		{
+			name:  "Range",
+			lines: lineRange{Start: 2, End: 3},
+			want:  "two\nthree\n",
+		},
+		{
+			name:  "Past the end",
+			lines: lineRange{Start: 3, End: 10},
+			want:  "three\n",
+		},
+		{
+			name:  "After the end",
+			lines: lineRange{Start: 4, End: 10},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, sliceLines(content, tt.lines), tt.want)
+		})
+	}
+}
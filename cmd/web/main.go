@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"html/template"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -21,13 +26,27 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
+// maxContentBytes is the most a snippet's TEXT content column can hold.
+const maxContentBytes = 65535
+
 type application struct {
 	logger         *slog.Logger
 	snippets       models.SnippetModelInterface
 	users          models.UserModelInterface
+	sessions       models.SessionModelInterface
+	collections    models.CollectionModelInterface
+	comments       models.CommentModelInterface
+	views          models.ViewModelInterface
+	viewRecorder   *viewRecorder
 	templateCache  map[string]*template.Template
 	formDecoder    *form.Decoder
 	sessionManager *scs.SessionManager
+	snippetUnlocks *failureLimiter
+	ipUnlocks      *failureLimiter
+	pageSize       int
+	maxExpiry      time.Duration
+	maxUpload      int64
+	embedOrigins   []string
 	debug          bool
 }
 
@@ -48,6 +67,18 @@ func main() {
 	addr := flag.String("addr", ":4000", "HTTP network address")
 	dsn := flag.String("dsn", fmt.Sprintf("%s:%s@/snippetbox?parseTime=true", sqlUser, sqlPass), "MySQL data source name")
 
+	pageSize := flag.Int("page-size", models.DefaultPageSize, "Number of snippets per listing page")
+	maxExpiry := flag.Duration("max-expiry", 0, "Longest a snippet may live for, 0 allows snippets that never expire")
+	maxUpload := flag.Int64("max-upload", maxContentBytes, "Largest file, in bytes, that can be uploaded as a snippet's content")
+
+	reapInterval := flag.Duration("reap-interval", time.Hour, "How often to delete expired snippets, old trash and expired sessions")
+	reapBatch := flag.Int("reap-batch", 1000, "Maximum rows the reaper deletes per statement")
+	purge := flag.Bool("purge", false, "Delete expired snippets, old trash and expired sessions once, then exit")
+
+	embedOrigins := flag.String("embed-origins", "", "Space-separated origins, such as https://wiki.example.com, allowed to embed snippets")
+
+	viewsFlushInterval := flag.Duration("views-flush-interval", time.Minute, "How often recorded snippet views are written to the database")
+
 	debug := flag.Bool("debug", false, "Enter debug mode")
 	setup := flag.Bool("setup", false, "Create DB")
 
@@ -64,6 +95,32 @@ func main() {
 		os.Exit(1)
 	}
 
+	origins, err := parseOrigins(*embedOrigins)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if *maxUpload < 1 || *maxUpload > maxContentBytes {
+		logger.Error(fmt.Sprintf("max-upload must be between 1 and %d bytes", maxContentBytes))
+		os.Exit(1)
+	}
+
+	if *reapInterval <= 0 {
+		logger.Error("reap-interval must be positive")
+		os.Exit(1)
+	}
+
+	if *reapBatch < 1 {
+		logger.Error("reap-batch must be at least 1")
+		os.Exit(1)
+	}
+
+	if *viewsFlushInterval <= 0 {
+		logger.Error("views-flush-interval must be positive")
+		os.Exit(1)
+	}
+
 	db, err := openDB(*dsn)
 	if err != nil {
 		logger.Error(err.Error())
@@ -81,7 +138,9 @@ func main() {
 	formDecoder := form.NewDecoder()
 
 	sessionManager := scs.New()
-	sessionManager.Store = mysqlstore.New(db)
+	// The reaper clears out expired sessions, so the store's own cleanup
+	// goroutine is turned off.
+	sessionManager.Store = mysqlstore.NewWithCleanupInterval(db, 0)
 	sessionManager.Lifetime = 12 * time.Hour
 	sessionManager.Cookie.Secure = true
 
@@ -89,12 +148,43 @@ func main() {
 		logger:         logger,
 		snippets:       &models.SnippetModel{DB: db},
 		users:          &models.UserModel{DB: db},
+		sessions:       &models.SessionModel{DB: db},
+		collections:    &models.CollectionModel{DB: db},
+		comments:       &models.CommentModel{DB: db},
+		views:          &models.ViewModel{DB: db},
+		viewRecorder:   newViewRecorder(),
 		templateCache:  templateCache,
 		formDecoder:    formDecoder,
 		sessionManager: sessionManager,
+		snippetUnlocks: newFailureLimiter(20, 15*time.Minute),
+		ipUnlocks:      newFailureLimiter(5, 15*time.Minute),
+		pageSize:       *pageSize,
+		maxExpiry:      *maxExpiry,
+		maxUpload:      *maxUpload,
+		embedOrigins:   origins,
 		debug:          *debug,
 	}
 
+	if *purge {
+		app.reap(context.Background(), *reapBatch)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		app.runReaper(ctx, *reapInterval, *reapBatch)
+	}()
+	go func() {
+		defer workers.Done()
+		app.runViewFlusher(ctx, *viewsFlushInterval)
+	}()
+
 	tlsConfig := &tls.Config{
 		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
 	}
@@ -109,11 +199,40 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 	}
 
+	shutdownErr := make(chan error, 1)
+
+	go func() {
+		<-ctx.Done()
+
+		logger.Info("shutting down server")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		shutdownErr <- server.Shutdown(shutdownCtx)
+	}()
+
 	logger.Info("starting server", slog.String("addr", *addr))
 
 	err = server.ListenAndServeTLS("./tls/cert.pem", "./tls/key.pem")
-	logger.Error(err.Error())
-	os.Exit(1)
+	if !errors.Is(err, http.ErrServerClosed) {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	err = <-shutdownErr
+	workers.Wait()
+
+	// Requests that were still being served when the flusher stopped may
+	// have recorded views since its last run.
+	app.flushViews()
+
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	logger.Info("stopped server")
 }
 
 func openDB(dsn string) (*sql.DB, error) {
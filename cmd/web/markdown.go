@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// markdown converts GitHub flavoured Markdown. It isn't given
+// html.WithUnsafe, so raw HTML and javascript: style links in the source are
+// dropped before the output even reaches markdownPolicy.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(
+		renderer.WithNodeRenderers(util.Prioritized(fencedCodeRenderer{}, 100)),
+	),
+)
+
+// markdownPolicy is the allow-list rendered Markdown is sanitised with. On top
+// of bluemonday's policy for user generated content, which has no scripts,
+// styles or event handler attributes, it keeps the classes highlighting
+// relies on and the disabled checkboxes of task lists.
+var markdownPolicy = func() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9 _-]+$`)).OnElements("pre", "code", "span")
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^(|checked|disabled)$`)).OnElements("input")
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+
+	return policy
+}()
+
+// renderMarkdown renders source as sanitised HTML. If the source can't be
+// converted it is shown as plain text instead.
+func renderMarkdown(source string) template.HTML {
+	var buf bytes.Buffer
+
+	err := markdown.Convert([]byte(source), &buf)
+	if err != nil {
+		return highlight(source, "plaintext")
+	}
+
+	return template.HTML(markdownPolicy.SanitizeBytes(buf.Bytes()))
+}
+
+// fencedCodeRenderer highlights fenced code blocks the same way as snippets
+// in the language named after the opening fence.
+type fencedCodeRenderer struct{}
+
+func (r fencedCodeRenderer) RegisterFuncs(registerer renderer.NodeRendererFuncRegisterer) {
+	registerer.Register(ast.KindFencedCodeBlock, r.render)
+}
+
+func (r fencedCodeRenderer) render(writer util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	block := node.(*ast.FencedCodeBlock)
+
+	var code bytes.Buffer
+
+	lines := block.Lines()
+	for i := range lines.Len() {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	language := "plaintext"
+	if block.Info != nil {
+		if name := block.Language(source); len(name) > 0 {
+			language = string(name)
+		}
+	}
+
+	_, err := writer.WriteString(string(highlight(code.String(), language)))
+	if err != nil {
+		return ast.WalkStop, err
+	}
+
+	return ast.WalkSkipChildren, nil
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/justinas/nosurf"
 )
 
+const contentSecurityPolicy = "default-src 'self'; style-src 'self' fonts.googleapis.com; font-src fonts.gstatic.com"
+
 func commonHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
-		response.Header().Set("Content-Security-Policy", "default-src 'self'; style-src 'self' fonts.googleapis.com; font-src fonts.gstatic.com")
+		response.Header().Set("Content-Security-Policy", contentSecurityPolicy+"; frame-ancestors 'none'")
 		response.Header().Set("Referrer-Policy", "origin-when-cross-origin")
 		response.Header().Set("X-Content-Type-Options", "nosniff")
 		response.Header().Set("X-Frame-Options", "deny")
@@ -21,6 +24,19 @@ func commonHeaders(next http.Handler) http.Handler {
 	})
 }
 
+// allowFraming lets the pages it wraps be framed by this site and the origins
+// in app.embedOrigins, replacing the deny that commonHeaders sets.
+func (app *application) allowFraming(next http.Handler) http.Handler {
+	ancestors := strings.Join(append([]string{"'self'"}, app.embedOrigins...), " ")
+
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.Header().Del("X-Frame-Options")
+		response.Header().Set("Content-Security-Policy", contentSecurityPolicy+"; frame-ancestors "+ancestors)
+
+		next.ServeHTTP(response, request)
+	})
+}
+
 func (app *application) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
 		var (
@@ -63,6 +79,21 @@ func (app *application) requireAuthentication(next http.Handler) http.Handler {
 	})
 }
 
+// maxFormOverhead is how much of a request body may be taken up by form
+// fields on top of the largest file that can be uploaded.
+const maxFormOverhead = 1 << 20
+
+// limitRequestBody caps the size of request bodies. It has to come before
+// noSurf, which parses the whole form, uploads included, to find the CSRF
+// token.
+func (app *application) limitRequestBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		request.Body = http.MaxBytesReader(response, request.Body, app.maxUpload+maxFormOverhead)
+
+		next.ServeHTTP(response, request)
+	})
+}
+
 func noSurf(next http.Handler) http.Handler {
 	csrfHandler := nosurf.New(next)
 	csrfHandler.SetBaseCookie(http.Cookie{
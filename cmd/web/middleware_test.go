@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,36 @@ import (
 	"github.com/Tyler-Meador/snippetbox/internal/assert"
 )
 
+func TestLimitRequestBody(t *testing.T) {
+	app := &application{maxUpload: 10}
+
+	var readErr error
+
+	next := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		_, readErr = io.ReadAll(request.Body)
+	})
+
+	tests := []struct {
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{name: "Within limit", size: 10 + maxFormOverhead},
+		{name: "Over limit", size: 11 + maxFormOverhead, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(make([]byte, tt.size)))
+
+			app.limitRequestBody(next).ServeHTTP(httptest.NewRecorder(), request)
+
+			var maxBytesError *http.MaxBytesError
+			assert.Equal(t, errors.As(readErr, &maxBytesError), tt.wantErr)
+		})
+	}
+}
+
 func TestCommonHeaders(t *testing.T) {
 	recorder := httptest.NewRecorder()
 
@@ -26,7 +57,7 @@ func TestCommonHeaders(t *testing.T) {
 
 	response := recorder.Result()
 
-	expectedValue := "default-src 'self'; style-src 'self' fonts.googleapis.com; font-src fonts.gstatic.com"
+	expectedValue := "default-src 'self'; style-src 'self' fonts.googleapis.com; font-src fonts.gstatic.com; frame-ancestors 'none'"
 	assert.Equal(t, response.Header.Get("Content-Security-Policy"), expectedValue)
 
 	expectedValue = "origin-when-cross-origin"
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// failureLimiter counts failed attempts per key over a fixed window. Once a
+// key has used up its failures it is blocked until the window that started
+// with its first failure has passed.
+type failureLimiter struct {
+	mu       sync.Mutex
+	max      int
+	window   time.Duration
+	failures map[string]*failureWindow
+	swept    time.Time
+}
+
+type failureWindow struct {
+	count int
+	reset time.Time
+}
+
+func newFailureLimiter(max int, window time.Duration) *failureLimiter {
+	return &failureLimiter{
+		max:      max,
+		window:   window,
+		failures: map[string]*failureWindow{},
+	}
+}
+
+// Allow reports whether key may make another attempt.
+func (limiter *failureLimiter) Allow(key string) bool {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	entry, ok := limiter.failures[key]
+	if !ok || time.Now().After(entry.reset) {
+		return true
+	}
+
+	return entry.count < limiter.max
+}
+
+// Fail records a failed attempt for key.
+func (limiter *failureLimiter) Fail(key string) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := time.Now()
+
+	limiter.sweep(now)
+
+	entry, ok := limiter.failures[key]
+	if !ok || now.After(entry.reset) {
+		entry = &failureWindow{reset: now.Add(limiter.window)}
+		limiter.failures[key] = entry
+	}
+
+	entry.count++
+}
+
+// sweep drops expired windows at most once per window so the map doesn't grow
+// without bound. It must be called with mu held.
+func (limiter *failureLimiter) sweep(now time.Time) {
+	if now.Sub(limiter.swept) < limiter.window {
+		return
+	}
+
+	for key, entry := range limiter.failures {
+		if now.After(entry.reset) {
+			delete(limiter.failures, key)
+		}
+	}
+
+	limiter.swept = now
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// reapStats is what a single reaper run deleted.
+type reapStats struct {
+	expiredSnippets int64
+	purgedTrash     int64
+	expiredSessions int64
+	batches         int
+}
+
+// runReaper reaps once per interval until ctx is cancelled. It returns once
+// any run in progress has finished its current batch.
+func (app *application) runReaper(ctx context.Context, interval time.Duration, batchSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.reap(ctx, batchSize)
+		}
+	}
+}
+
+// reap deletes expired snippets, trash past its retention period and expired
+// sessions, batchSize rows at a time, and logs what it did.
+func (app *application) reap(ctx context.Context, batchSize int) reapStats {
+	var stats reapStats
+
+	start := time.Now()
+
+	jobs := []struct {
+		name   string
+		delete func(limit int) (int64, error)
+		count  *int64
+	}{
+		{"expired snippets", app.snippets.DeleteExpired, &stats.expiredSnippets},
+		{"trashed snippets", app.snippets.PurgeTrash, &stats.purgedTrash},
+		{"expired sessions", app.sessions.DeleteExpired, &stats.expiredSessions},
+	}
+
+	for _, job := range jobs {
+		deleted, batches, err := deleteInBatches(ctx, batchSize, job.delete)
+		*job.count += deleted
+		stats.batches += batches
+
+		if err != nil {
+			app.logger.Error("reaper failed", slog.String("job", job.name), slog.String("error", err.Error()))
+		}
+	}
+
+	app.logger.Info("reaper run",
+		slog.Int64("expired_snippets", stats.expiredSnippets),
+		slog.Int64("purged_trash", stats.purgedTrash),
+		slog.Int64("expired_sessions", stats.expiredSessions),
+		slog.Int("batches", stats.batches),
+		slog.Duration("duration", time.Since(start)),
+	)
+
+	return stats
+}
+
+// deleteInBatches calls deleteBatch until it deletes fewer than batchSize
+// rows, so no single statement holds locks on a large part of a table. It
+// stops early if ctx is cancelled.
+func deleteInBatches(ctx context.Context, batchSize int, deleteBatch func(limit int) (int64, error)) (int64, int, error) {
+	var total int64
+	var batches int
+
+	for ctx.Err() == nil {
+		deleted, err := deleteBatch(batchSize)
+		if err != nil {
+			return total, batches, err
+		}
+
+		total += deleted
+		batches++
+
+		if deleted == 0 || deleted < int64(batchSize) {
+			break
+		}
+	}
+
+	return total, batches, nil
+}
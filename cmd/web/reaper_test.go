@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+func TestDeleteInBatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        int64
+		failAfter   int
+		wantDeleted int64
+		wantBatches int
+		wantErr     bool
+	}{
+		{
+			name:        "Nothing to delete",
+			rows:        0,
+			wantDeleted: 0,
+			wantBatches: 1,
+		},
+		{
+			name:        "Partial batch",
+			rows:        3,
+			wantDeleted: 3,
+			wantBatches: 1,
+		},
+		{
+			name:        "Exact batches",
+			rows:        20,
+			wantDeleted: 20,
+			wantBatches: 3,
+		},
+		{
+			name:        "Several batches",
+			rows:        25,
+			wantDeleted: 25,
+			wantBatches: 3,
+		},
+		{
+			name:        "Error",
+			rows:        25,
+			failAfter:   1,
+			wantDeleted: 10,
+			wantBatches: 1,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining := tt.rows
+			calls := 0
+
+			deleteBatch := func(limit int) (int64, error) {
+				calls++
+				if tt.failAfter > 0 && calls > tt.failAfter {
+					return 0, errors.New("boom")
+				}
+
+				deleted := min(remaining, int64(limit))
+				remaining -= deleted
+				return deleted, nil
+			}
+
+			deleted, batches, err := deleteInBatches(context.Background(), 10, deleteBatch)
+
+			assert.Equal(t, deleted, tt.wantDeleted)
+			assert.Equal(t, batches, tt.wantBatches)
+			assert.Equal(t, err != nil, tt.wantErr)
+		})
+	}
+}
+
+func TestDeleteInBatchesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	deleteBatch := func(limit int) (int64, error) {
+		cancel()
+		return int64(limit), nil
+	}
+
+	deleted, batches, err := deleteInBatches(ctx, 10, deleteBatch)
+
+	assert.Equal(t, deleted, int64(10))
+	assert.Equal(t, batches, 1)
+	assert.NilError(t, err)
+}
+
+func TestReap(t *testing.T) {
+	app := newTestApplication(t)
+
+	stats := app.reap(context.Background(), 10)
+
+	assert.Equal(t, stats.expiredSnippets, int64(0))
+	assert.Equal(t, stats.batches, 3)
+}
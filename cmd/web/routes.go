@@ -14,10 +14,22 @@ func (app *application) routes() http.Handler {
 
 	mux.HandleFunc("GET /ping", ping)
 
-	dynamic := alice.New(app.sessionManager.LoadAndSave, noSurf, app.authenticate)
+	dynamic := alice.New(app.limitRequestBody, app.sessionManager.LoadAndSave, noSurf, app.authenticate)
 
 	mux.Handle("GET /{$}", dynamic.ThenFunc(app.home))
+	mux.Handle("GET /search", dynamic.ThenFunc(app.search))
+	mux.Handle("GET /compare", dynamic.ThenFunc(app.compare))
+	mux.Handle("GET /tag/{name}", dynamic.ThenFunc(app.tagView))
 	mux.Handle("GET /snippet/view/{id}", dynamic.ThenFunc(app.snippetView))
+	mux.Handle("POST /snippet/view/{id}/unlock", dynamic.ThenFunc(app.snippetUnlockPost))
+	mux.Handle("GET /snippet/view/{id}/history", dynamic.ThenFunc(app.snippetHistory))
+	mux.Handle("GET /snippet/view/{id}/diff", dynamic.ThenFunc(app.snippetDiff))
+	mux.Handle("GET /snippet/raw/{id}", dynamic.ThenFunc(app.snippetRaw))
+	mux.Handle("GET /snippet/raw/{id}/{file}", dynamic.ThenFunc(app.snippetRawFile))
+	mux.Handle("GET /snippet/download/{id}", dynamic.ThenFunc(app.snippetDownload))
+	mux.Handle("GET /snippet/embed/{id}", dynamic.Append(app.allowFraming).ThenFunc(app.snippetEmbed))
+	mux.Handle("GET /oembed", dynamic.ThenFunc(app.oembed))
+	mux.Handle("GET /collection/{slug}", dynamic.ThenFunc(app.collectionView))
 	mux.Handle("GET /user/signup", dynamic.ThenFunc(app.userSignup))
 	mux.Handle("POST /user/signup", dynamic.ThenFunc(app.userSignupPost))
 	mux.Handle("GET /user/login", dynamic.ThenFunc(app.userLogin))
@@ -28,8 +40,25 @@ func (app *application) routes() http.Handler {
 
 	mux.Handle("GET /snippet/create", protected.ThenFunc(app.snippetCreate))
 	mux.Handle("POST /snippet/create", protected.ThenFunc(app.snippetCreatePost))
+	mux.Handle("GET /snippet/edit/{id}", protected.ThenFunc(app.snippetEdit))
+	mux.Handle("POST /snippet/edit/{id}", protected.ThenFunc(app.snippetEditPost))
+	mux.Handle("POST /snippet/delete/{id}", protected.ThenFunc(app.snippetDeletePost))
+	mux.Handle("POST /snippet/restore/{id}", protected.ThenFunc(app.snippetRestorePost))
+	mux.Handle("POST /snippet/fork/{id}", protected.ThenFunc(app.snippetForkPost))
+	mux.Handle("POST /snippet/view/{id}/comments", protected.ThenFunc(app.commentCreatePost))
+	mux.Handle("GET /comment/edit/{id}", protected.ThenFunc(app.commentEdit))
+	mux.Handle("POST /comment/edit/{id}", protected.ThenFunc(app.commentEditPost))
+	mux.Handle("POST /comment/delete/{id}", protected.ThenFunc(app.commentDeletePost))
+	mux.Handle("POST /snippet/star/{id}", protected.ThenFunc(app.snippetStarPost))
+	mux.Handle("POST /snippet/unstar/{id}", protected.ThenFunc(app.snippetUnstarPost))
+	mux.Handle("POST /collection/create", protected.ThenFunc(app.collectionCreatePost))
+	mux.Handle("POST /collection/add", protected.ThenFunc(app.collectionAddPost))
+	mux.Handle("POST /collection/{slug}/delete", protected.ThenFunc(app.collectionDeletePost))
+	mux.Handle("POST /collection/{slug}/remove/{id}", protected.ThenFunc(app.collectionRemovePost))
+	mux.Handle("POST /collection/{slug}/move/{id}", protected.ThenFunc(app.collectionMovePost))
 	mux.Handle("POST /user/logout", protected.ThenFunc(app.userLogoutPost))
 	mux.Handle("GET /account/view", protected.ThenFunc(app.accountView))
+	mux.Handle("GET /account/starred", protected.ThenFunc(app.accountStarred))
 	mux.Handle("GET /account/password/update", protected.ThenFunc(app.accountPasswordUpdate))
 	mux.Handle("POST /account/password/update", protected.ThenFunc(app.accountPasswordUpdatePost))
 
@@ -3,9 +3,11 @@ package main
 import (
 	"html/template"
 	"io/fs"
+	"net/url"
 	"path/filepath"
 	"time"
 
+	"github.com/Tyler-Meador/snippetbox/internal/diff"
 	"github.com/Tyler-Meador/snippetbox/internal/models"
 	"github.com/Tyler-Meador/snippetbox/ui"
 )
@@ -13,16 +15,133 @@ import (
 type templateData struct {
 	Snippet         models.Snippet
 	Snippets        []models.Snippet
+	Trash           []models.Snippet
+	Collection      models.Collection
+	Collections     []models.Collection
+	Starred         bool
+	Comment         models.Comment
+	Comments        []models.Comment
+	ViewStats       viewStats
+	Locked          bool
+	EmbedCode       string
+	OEmbedURL       string
+	Lines           lineRange
+	Page            models.SnippetPage
+	Query           string
+	Tag             string
+	Languages       []language
+	MaxExpiry       time.Duration
+	Search          models.SearchPage
+	History         []historyEntry
+	Diff            diffView
 	CurrentYear     int
 	Form            any
 	Flash           string
 	IsAuthenticated bool
+	AuthenticatedID int
 	CSRFToken       string
 	User            models.User
 }
 
+type historyEntry struct {
+	models.Revision
+	NextID int
+}
+
+// viewStats is the stats panel owners see on their snippets. Bars chart the
+// daily views; they're drawn as SVG because the CSP doesn't allow inline
+// styles.
+type viewStats struct {
+	models.ViewStats
+	Show bool
+	Bars []statsBar
+}
+
+type statsBar struct {
+	models.DailyViews
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// Dimensions of the daily views chart in SVG user units.
+const (
+	statsBarWidth    = 10
+	statsBarGap      = 2
+	statsChartHeight = 60
+)
+
+func newViewStats(stats models.ViewStats) viewStats {
+	view := viewStats{ViewStats: stats, Show: true}
+
+	most := 0
+	for _, day := range stats.Days {
+		most = max(most, day.Views)
+	}
+
+	for i, day := range stats.Days {
+		height := 0
+		if most > 0 {
+			height = day.Views * statsChartHeight / most
+		}
+
+		view.Bars = append(view.Bars, statsBar{
+			DailyViews: day,
+			X:          i * statsBarWidth,
+			Y:          statsChartHeight - height,
+			Width:      statsBarWidth - statsBarGap,
+			Height:     height,
+		})
+	}
+
+	return view
+}
+
+// ChartWidth is the width of the daily views chart in SVG user units.
+func (view viewStats) ChartWidth() int {
+	return len(view.Bars) * statsBarWidth
+}
+
+type diffView struct {
+	FromLabel        string
+	FromTitle        string
+	ToLabel          string
+	ToTitle          string
+	Split            bool
+	IgnoreWhitespace bool
+	TooLarge         bool
+	Lines            []diff.Line
+	Rows             []diff.Row
+	UnifiedURL       string
+	SplitURL         string
+	WhitespaceURL    string
+}
+
+// commentNode is what the recursive "comment" template renders: a comment
+// along with the page it is on, for the links and forms under it.
+type commentNode struct {
+	Comment models.Comment
+	Page    templateData
+}
+
+func newCommentNode(comment models.Comment, page templateData) commentNode {
+	return commentNode{Comment: comment, Page: page}
+}
+
 var functions = template.FuncMap{
-	"humanDate": humanDate,
+	"humanDate":        humanDate,
+	"purgeDate":        purgeDate,
+	"highlight":        highlight,
+	"highlightLines":   highlightLines,
+	"highlightExcerpt": highlightExcerpt,
+	"markdown":         renderMarkdown,
+	"languageLabel":    languageLabel,
+	"expiryDate":       expiryDate,
+	"files":            snippetFiles,
+	"add":              add,
+	"pathEscape":       url.PathEscape,
+	"commentNode":      newCommentNode,
 }
 
 func newTemplateCache() (map[string]*template.Template, error) {
@@ -38,6 +157,7 @@ func newTemplateCache() (map[string]*template.Template, error) {
 
 		patterns := []string{
 			"html/base.html",
+			"html/embed.html",
 			"html/partials/*html",
 			page,
 		}
@@ -60,3 +180,19 @@ func humanDate(humanTime time.Time) string {
 
 	return humanTime.UTC().Format("02 Jan 2006 at 15:04")
 }
+
+func add(a, b int) int {
+	return a + b
+}
+
+func expiryDate(expires time.Time) string {
+	if expires.IsZero() {
+		return "Never"
+	}
+
+	return humanDate(expires)
+}
+
+func purgeDate(deleted time.Time) time.Time {
+	return deleted.AddDate(0, 0, models.TrashRetentionDays)
+}
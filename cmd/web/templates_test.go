@@ -37,3 +37,207 @@ func TestHumanDate(test *testing.T) {
 		})
 	}
 }
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		language string
+		want     string
+	}{
+		{
+			name:     "Go",
+			code:     "package main",
+			language: "go",
+			want:     `<span class="kn">package</span>`,
+		},
+		{
+			name:     "Line numbers",
+			code:     "one\ntwo",
+			language: "plaintext",
+			want:     `<span class="ln">2</span>`,
+		},
+		{
+			name:     "Unknown language",
+			code:     "package main",
+			language: "klingon",
+			want:     `<span class="cl">package main</span>`,
+		},
+		{
+			name:     "Escapes markup",
+			code:     "<script>alert(1)</script>",
+			language: "plaintext",
+			want:     "&lt;script&gt;alert(1)&lt;/script&gt;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := string(highlight(tt.code, tt.language))
+
+			assert.StringContains(t, html, tt.want)
+			assert.StringNotContains(t, html, "style=")
+		})
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "Heading",
+			source: "# Hello",
+			want:   "<h1>Hello</h1>",
+		},
+		{
+			name:   "Table",
+			source: "| a | b |\n| - | - |\n| 1 | 2 |",
+			want:   "<td>1</td>",
+		},
+		{
+			name:   "Link",
+			source: "[docs](https://example.com)",
+			want:   `<a href="https://example.com" rel="nofollow noopener" target="_blank">docs</a>`,
+		},
+		{
+			name:   "Fenced code",
+			source: "```go\npackage main\n```",
+			want:   `<span class="kn">package</span>`,
+		},
+		{
+			name:   "Fenced code escapes markup",
+			source: "```html\n<script>alert(1)</script>\n```",
+			want:   "&lt;",
+		},
+		{
+			name:   "Script tag",
+			source: "<script>alert(1)</script>",
+		},
+		{
+			name:   "Inline script tag",
+			source: "hello <script>alert(1)</script>",
+			want:   "hello",
+		},
+		{
+			name:   "Event handler",
+			source: `<img src="x" onerror="alert(1)">`,
+		},
+		{
+			name:   "SVG onload",
+			source: "<svg onload=alert(1)>",
+		},
+		{
+			name:   "Style element",
+			source: "<style>body { display: none }</style>",
+		},
+		{
+			name:   "Style attribute",
+			source: `<p style="color: red">hi</p>`,
+		},
+		{
+			name:   "Iframe",
+			source: `<iframe src="https://evil.example.com"></iframe>`,
+		},
+		{
+			name:   "Javascript link",
+			source: "[click](javascript:alert(1))",
+			want:   "click",
+		},
+		{
+			name:   "Encoded javascript link",
+			source: "[click](&#x6A;avascript:alert(1))",
+			want:   "click",
+		},
+		{
+			name:   "Javascript image",
+			source: "![x](javascript:alert(1))",
+		},
+		{
+			name:   "Data link",
+			source: "[click](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)",
+			want:   "click",
+		},
+		{
+			name:   "Autolink",
+			source: "<javascript:alert(1)>",
+		},
+		{
+			name:   "Task list",
+			source: "- [x] done",
+			want:   `<input checked="" disabled="" type="checkbox"`,
+		},
+	}
+
+	forbidden := []string{"<script", "<style", "<iframe", "<svg", "style=", "onerror", "onload", `="javascript:`, `="data:`}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := string(renderMarkdown(tt.source))
+
+			assert.StringContains(t, html, tt.want)
+
+			for _, s := range forbidden {
+				assert.StringNotContains(t, html, s)
+			}
+		})
+	}
+}
+
+func TestHighlightLines(t *testing.T) {
+	code := "one\ntwo\nthree"
+
+	t.Run("Links lines", func(t *testing.T) {
+		html := string(highlightLines(code, "plaintext", 0, lineRange{}))
+
+		assert.StringContains(t, html, `<span class="ln" id="L2"><a class="lnlinks" href="#L2">2</a></span>`)
+		assert.StringNotContains(t, html, "hl")
+	})
+
+	t.Run("Highlights range", func(t *testing.T) {
+		html := string(highlightLines(code, "plaintext", 0, lineRange{Start: 2, End: 3}))
+
+		assert.StringContains(t, html, `<span class="line hl"><span class="ln" id="L2">`)
+		assert.StringContains(t, html, `<span class="line hl"><span class="ln" id="L3">`)
+		assert.StringContains(t, html, `<span class="line"><span class="ln" id="L1">`)
+	})
+
+	t.Run("Later files", func(t *testing.T) {
+		html := string(highlightLines(code, "plaintext", 2, lineRange{Start: 2, End: 3}))
+
+		assert.StringContains(t, html, `id="f2-L2"`)
+		assert.StringNotContains(t, html, "hl")
+	})
+
+	t.Run("Excerpt", func(t *testing.T) {
+		html := string(highlightExcerpt(code, "plaintext", lineRange{Start: 2, End: 2}))
+
+		assert.StringContains(t, html, `<span class="ln">2</span><span class="cl">two`)
+		assert.StringNotContains(t, html, "one")
+		assert.StringNotContains(t, html, "three")
+	})
+}
+
+func TestLanguageForFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "main.go", want: "go"},
+		{name: "Dockerfile", want: "dockerfile"},
+		{name: "Makefile", want: "makefile"},
+		{name: "config.yml", want: "yaml"},
+		{name: "README.md", want: "markdown"},
+		{name: "notes.txt", want: "plaintext"},
+		{name: "Main.elm", want: "plaintext"},
+		{name: "no-extension", want: "plaintext"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, languageForFilename(tt.name), tt.want)
+		})
+	}
+}
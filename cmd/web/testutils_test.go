@@ -5,6 +5,7 @@ import (
 	"html"
 	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptest"
@@ -40,9 +41,19 @@ func newTestApplication(t *testing.T) *application {
 		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
 		snippets:       &mocks.SnippetModel{},
 		users:          &mocks.UserModel{},
+		sessions:       &mocks.SessionModel{},
+		collections:    &mocks.CollectionModel{},
+		comments:       &mocks.CommentModel{},
+		views:          &mocks.ViewModel{},
+		viewRecorder:   newViewRecorder(),
 		templateCache:  templateCache,
 		formDecoder:    formDecoder,
 		sessionManager: sessionManager,
+		snippetUnlocks: newFailureLimiter(20, 15*time.Minute),
+		ipUnlocks:      newFailureLimiter(5, 15*time.Minute),
+		pageSize:       1,
+		maxUpload:      1 << 10,
+		embedOrigins:   []string{"https://wiki.example.com"},
 	}
 }
 
@@ -105,3 +116,61 @@ func (ts *testServer) postForm(t *testing.T, urlPath string, form url.Values) (i
 
 	return response.StatusCode, response.Header, string(body)
 }
+
+// postMultipart posts form as multipart/form-data, along with a file named
+// fileName holding content in the upload field if fileName isn't empty.
+func (ts *testServer) postMultipart(t *testing.T, urlPath string, form url.Values, fileName string, content []byte) (int, http.Header, string) {
+	var buf bytes.Buffer
+
+	writer := multipart.NewWriter(&buf)
+
+	for key, values := range form {
+		for _, value := range values {
+			err := writer.WriteField(key, value)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if fileName != "" {
+		part, err := writer.CreateFormFile("upload", fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		part.Write(content)
+	}
+
+	err := writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := ts.Client().Post(ts.URL+urlPath, writer.FormDataContentType(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body = bytes.TrimSpace(body)
+
+	return response.StatusCode, response.Header, string(body)
+}
+
+func (ts *testServer) login(t *testing.T) {
+	_, _, body := ts.get(t, "/user/login")
+	validCSRFToken := extractCSRFToken(t, body)
+
+	form := url.Values{}
+	form.Add("email", "alice@example.com")
+	form.Add("password", "pa$$word")
+	form.Add("csrf_token", validCSRFToken)
+
+	ts.postForm(t, "/user/login", form)
+}
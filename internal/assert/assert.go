@@ -28,3 +28,11 @@ func NilError(t *testing.T, actual error) {
 		t.Errorf("got: %v; expected: nil", actual)
 	}
 }
+
+func StringNotContains(t *testing.T, actual, unexpectedSubstring string) {
+	t.Helper()
+
+	if strings.Contains(actual, unexpectedSubstring) {
+		t.Errorf("got: %q; expected not to contain: %q", actual, unexpectedSubstring)
+	}
+}
@@ -0,0 +1,307 @@
+package diff
+
+import (
+	"errors"
+	"strings"
+)
+
+// Limits on the work a diff may take. Backtracking keeps O(D²) state for an
+// edit distance of D, so both are capped to keep large or unrelated inputs
+// from using unbounded memory and time.
+const (
+	// MaxLines caps the number of lines compared, not counting those the
+	// inputs share at the start and end.
+	MaxLines = 20000
+	// MaxEdits caps the number of lines inserted and deleted.
+	MaxEdits = 1000
+)
+
+// ErrTooLarge is returned when the inputs exceed MaxLines or MaxEdits.
+var ErrTooLarge = errors.New("diff: too large")
+
+type Kind int
+
+const (
+	Equal Kind = iota
+	Insert
+	Delete
+)
+
+type Line struct {
+	Kind    Kind
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+type Row struct {
+	Left  *Line
+	Right *Line
+}
+
+func (line Line) Class() string {
+	switch line.Kind {
+	case Insert:
+		return "insert"
+	case Delete:
+		return "delete"
+	default:
+		return "equal"
+	}
+}
+
+func (line Line) Marker() string {
+	switch line.Kind {
+	case Insert:
+		return "+"
+	case Delete:
+		return "-"
+	default:
+		return " "
+	}
+}
+
+func SplitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Options changes how Compare matches lines.
+type Options struct {
+	// IgnoreWhitespace treats lines that differ only in whitespace as equal,
+	// like git diff -w.
+	IgnoreWhitespace bool
+}
+
+// Lines returns the line-level edit script turning a into b, computed with
+// Myers' O(ND) algorithm, or ErrTooLarge.
+func Lines(a, b string) ([]Line, error) {
+	return compare(SplitLines(a), SplitLines(b))
+}
+
+// Compare is Lines with options. Lines that are only equal once whitespace
+// is ignored are shown as they are in b.
+func Compare(a, b string, options Options) ([]Line, error) {
+	linesA, linesB := SplitLines(a), SplitLines(b)
+
+	if !options.IgnoreWhitespace {
+		return compare(linesA, linesB)
+	}
+
+	lines, err := compare(stripSpace(linesA), stripSpace(linesB))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range lines {
+		if line.NewLine != 0 {
+			lines[i].Text = linesB[line.NewLine-1]
+		} else {
+			lines[i].Text = linesA[line.OldLine-1]
+		}
+	}
+
+	return lines, nil
+}
+
+func stripSpace(lines []string) []string {
+	stripped := make([]string, len(lines))
+
+	for i, line := range lines {
+		stripped[i] = strings.Join(strings.Fields(line), "")
+	}
+
+	return stripped
+}
+
+func compare(a, b []string) ([]Line, error) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(middleA)+len(middleB) > MaxLines {
+		return nil, ErrTooLarge
+	}
+
+	edits, ok := myers(middleA, middleB)
+	if !ok {
+		return nil, ErrTooLarge
+	}
+
+	var lines []Line
+
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, Line{Kind: Equal, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+
+	for _, line := range edits {
+		if line.OldLine != 0 {
+			line.OldLine += prefix
+		}
+		if line.NewLine != 0 {
+			line.NewLine += prefix
+		}
+		lines = append(lines, line)
+	}
+
+	for i := suffix; i > 0; i-- {
+		lines = append(lines, Line{Kind: Equal, Text: a[len(a)-i], OldLine: len(a) - i + 1, NewLine: len(b) - i + 1})
+	}
+
+	return lines, nil
+}
+
+// myers returns the edit script turning a into b, or false if that takes
+// more than MaxEdits insertions and deletions.
+func myers(a, b []string) ([]Line, bool) {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil, true
+	}
+
+	offset := max
+	v := make([]int, 2*max+2)
+
+	// trace[d] keeps the furthest reaching x for diagonals -d..d as they
+	// stood before round d, which is all backtracking needs.
+	var trace [][]int
+
+	for d := 0; d <= min(max, MaxEdits); d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func backtrack(trace [][]int, a, b []string) []Line {
+	x, y := len(a), len(b)
+
+	var reversed []Line
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		k := x - y
+
+		previous := func(k int) int {
+			if k < -d || k > d {
+				return -1
+			}
+			return trace[d][k+d]
+		}
+
+		var prevK int
+		if k == -d || (k != d && previous(k-1) < previous(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := 0
+		if d > 0 {
+			prevX = previous(prevK)
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY && x > 0 && y > 0 {
+			reversed = append(reversed, Line{Kind: Equal, Text: a[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+
+		if d == 0 {
+			break
+		}
+
+		if x == prevX {
+			reversed = append(reversed, Line{Kind: Insert, Text: b[y-1], NewLine: y})
+		} else {
+			reversed = append(reversed, Line{Kind: Delete, Text: a[x-1], OldLine: x})
+		}
+
+		x, y = prevX, prevY
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+
+	return lines
+}
+
+// Split pairs deleted and inserted lines so they can be shown side by side.
+func Split(lines []Line) []Row {
+	var rows []Row
+
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == Equal {
+			rows = append(rows, Row{Left: &lines[i], Right: &lines[i]})
+			i++
+			continue
+		}
+
+		var deleted, inserted []*Line
+		for ; i < len(lines) && lines[i].Kind != Equal; i++ {
+			if lines[i].Kind == Delete {
+				deleted = append(deleted, &lines[i])
+			} else {
+				inserted = append(inserted, &lines[i])
+			}
+		}
+
+		for j := 0; j < len(deleted) || j < len(inserted); j++ {
+			var row Row
+			if j < len(deleted) {
+				row.Left = deleted[j]
+			}
+			if j < len(inserted) {
+				row.Right = inserted[j]
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+func Changed(lines []Line) bool {
+	for _, line := range lines {
+		if line.Kind != Equal {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,203 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+func render(lines []Line) string {
+	var builder strings.Builder
+
+	for _, line := range lines {
+		builder.WriteString(line.Marker() + line.Text + "\n")
+	}
+
+	return builder.String()
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{
+			name: "Identical",
+			a:    "one\ntwo\n",
+			b:    "one\ntwo\n",
+			want: " one\n two\n",
+		},
+		{
+			name: "Empty",
+			a:    "",
+			b:    "",
+			want: "",
+		},
+		{
+			name: "All inserted",
+			a:    "",
+			b:    "one\ntwo",
+			want: "+one\n+two\n",
+		},
+		{
+			name: "All deleted",
+			a:    "one\ntwo",
+			b:    "",
+			want: "-one\n-two\n",
+		},
+		{
+			name: "Changed line",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: " one\n-two\n+2\n three\n",
+		},
+		{
+			name: "Interleaved",
+			a:    "a\nb\nc\na\nb\nb\na",
+			b:    "c\nb\na\nb\na\nc",
+			want: "-a\n-b\n c\n+b\n a\n b\n-b\n a\n+c\n",
+		},
+		{
+			name: "CRLF",
+			a:    "one\r\ntwo\r\n",
+			b:    "one\ntwo\n",
+			want: " one\n two\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := Lines(tt.a, tt.b)
+			assert.NilError(t, err)
+			assert.Equal(t, render(lines), tt.want)
+		})
+	}
+}
+
+// numbered returns n lines, each prefixed so no two inputs share a line.
+func numbered(prefix string, n int) string {
+	var builder strings.Builder
+
+	for i := range n {
+		fmt.Fprintf(&builder, "%s %d\n", prefix, i)
+	}
+
+	return builder.String()
+}
+
+func TestLinesTooLarge(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+	}{
+		{
+			name: "Too many lines",
+			a:    numbered("a", MaxLines),
+			b:    numbered("b", 1),
+		},
+		{
+			name: "Too many edits",
+			a:    numbered("a", MaxEdits),
+			b:    numbered("b", MaxEdits),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Lines(tt.a, tt.b)
+			assert.Equal(t, errors.Is(err, ErrTooLarge), true)
+		})
+	}
+
+	t.Run("Large but similar", func(t *testing.T) {
+		a := numbered("a", MaxLines)
+		b := strings.Replace(a, "a 5000\n", "b 5000\n", 1)
+
+		lines, err := Lines(a, b)
+		assert.NilError(t, err)
+		assert.Equal(t, len(lines), MaxLines+1)
+	})
+}
+
+func TestCompareIgnoreWhitespace(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{
+			name: "Indentation",
+			a:    "if x {\nreturn\n}",
+			b:    "if x {\n\treturn\n}",
+			want: " if x {\n \treturn\n }\n",
+		},
+		{
+			name: "Inner and trailing spaces",
+			a:    "key = value  \nother",
+			b:    "key=value\nother",
+			want: " key=value\n other\n",
+		},
+		{
+			name: "Real change",
+			a:    "  one\ntwo",
+			b:    "one\n  three",
+			want: " one\n-two\n+  three\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := Compare(tt.a, tt.b, Options{IgnoreWhitespace: true})
+			assert.NilError(t, err)
+			assert.Equal(t, render(lines), tt.want)
+		})
+	}
+
+	t.Run("Off", func(t *testing.T) {
+		lines, err := Compare("  one", "one", Options{})
+		assert.NilError(t, err)
+		assert.Equal(t, render(lines), "-  one\n+one\n")
+	})
+}
+
+func TestLinesNumbering(t *testing.T) {
+	lines, err := Lines("a\nb\nc", "a\nx\nc\nd")
+	assert.NilError(t, err)
+
+	want := []Line{
+		{Kind: Equal, Text: "a", OldLine: 1, NewLine: 1},
+		{Kind: Delete, Text: "b", OldLine: 2},
+		{Kind: Insert, Text: "x", NewLine: 2},
+		{Kind: Equal, Text: "c", OldLine: 3, NewLine: 3},
+		{Kind: Insert, Text: "d", NewLine: 4},
+	}
+
+	assert.Equal(t, len(lines), len(want))
+
+	for i := range want {
+		if i < len(lines) {
+			assert.Equal(t, lines[i], want[i])
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	lines, err := Lines("a\nb\nc\nd", "a\nx\ny\nd")
+	assert.NilError(t, err)
+
+	rows := Split(lines)
+
+	assert.Equal(t, len(rows), 4)
+	assert.Equal(t, rows[1].Left.Text, "b")
+	assert.Equal(t, rows[1].Right.Text, "x")
+	assert.Equal(t, rows[2].Left.Text, "c")
+	assert.Equal(t, rows[2].Right.Text, "y")
+	assert.Equal(t, rows[3].Left, rows[3].Right)
+}
@@ -0,0 +1,254 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type CollectionModelInterface interface {
+	Insert(userID int, name string) (int, error)
+	Get(slug string) (Collection, error)
+	ByUser(userID int) ([]Collection, error)
+	Delete(id int) error
+	AddSnippet(id int, snippetID int) error
+	RemoveSnippet(id int, snippetID int) error
+	MoveSnippet(id int, snippetID int, offset int) error
+}
+
+// Collection is a named, ordered list of snippets curated by one user and
+// shared through its slug.
+type Collection struct {
+	ID       int
+	UserID   int
+	UserName string
+	Name     string
+	Slug     string
+	Created  time.Time
+	Snippets []Snippet
+}
+
+type CollectionModel struct {
+	DB *sql.DB
+}
+
+func (model *CollectionModel) Insert(userID int, name string) (int, error) {
+	slug, err := newSlug()
+	if err != nil {
+		return 0, err
+	}
+
+	statement := `INSERT INTO collections (user_id, name, slug, created) VALUES(?, ?, ?, UTC_TIMESTAMP())`
+
+	result, err := model.DB.Exec(statement, userID, name, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// Get returns the collection with the given slug along with the snippets in
+// it that haven't expired or been deleted. Callers are responsible for
+// hiding snippets the viewer isn't allowed to see.
+func (model *CollectionModel) Get(slug string) (Collection, error) {
+	statement := `SELECT c.id, c.user_id, u.name, c.name, c.slug, c.created FROM collections c
+	INNER JOIN users u ON u.id = c.user_id
+	WHERE c.slug = ?`
+
+	var collection Collection
+
+	err := model.DB.QueryRow(statement, slug).Scan(&collection.ID, &collection.UserID, &collection.UserName, &collection.Name,
+		&collection.Slug, &collection.Created)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Collection{}, ErrNoRecord
+		} else {
+			return Collection{}, err
+		}
+	}
+
+	collection.Snippets, err = model.snippets(collection.ID)
+	if err != nil {
+		return Collection{}, err
+	}
+
+	return collection, nil
+}
+
+// ByUser returns every collection userID owns, newest first, each with its
+// snippets loaded.
+func (model *CollectionModel) ByUser(userID int) ([]Collection, error) {
+	statement := `SELECT c.id, c.user_id, u.name, c.name, c.slug, c.created FROM collections c
+	INNER JOIN users u ON u.id = c.user_id
+	WHERE c.user_id = ? ORDER BY c.id DESC`
+
+	rows, err := model.DB.Query(statement, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var collections []Collection
+
+	for rows.Next() {
+		var collection Collection
+
+		err = rows.Scan(&collection.ID, &collection.UserID, &collection.UserName, &collection.Name, &collection.Slug, &collection.Created)
+		if err != nil {
+			return nil, err
+		}
+
+		collections = append(collections, collection)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range collections {
+		collections[i].Snippets, err = model.snippets(collections[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return collections, nil
+}
+
+func (model *CollectionModel) snippets(id int) ([]Snippet, error) {
+	statement := `SELECT ` + snippetColumns + ` FROM collection_snippets cs
+	INNER JOIN snippets s ON s.id = cs.snippet_id
+	INNER JOIN users u ON u.id = s.user_id
+	WHERE (s.expires IS NULL OR s.expires > UTC_TIMESTAMP()) AND s.deleted IS NULL AND cs.collection_id = ?
+	ORDER BY cs.position`
+
+	rows, err := model.DB.Query(statement, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSnippets(rows)
+}
+
+func (model *CollectionModel) Delete(id int) error {
+	statement := `DELETE FROM collections WHERE id = ?`
+
+	_, err := model.DB.Exec(statement, id)
+
+	return err
+}
+
+// AddSnippet appends snippetID to the end of collection id. It returns
+// ErrDuplicateSnippet if the snippet is already in the collection.
+func (model *CollectionModel) AddSnippet(id int, snippetID int) error {
+	statement := `INSERT INTO collection_snippets (collection_id, snippet_id, position)
+	SELECT ?, ?, COALESCE(MAX(position), 0) + 1 FROM collection_snippets WHERE collection_id = ?`
+
+	_, err := model.DB.Exec(statement, id, snippetID, id)
+	if err != nil {
+		var mySQLError *mysql.MySQLError
+		if errors.As(err, &mySQLError) && mySQLError.Number == 1062 {
+			return ErrDuplicateSnippet
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (model *CollectionModel) RemoveSnippet(id int, snippetID int) error {
+	statement := `DELETE FROM collection_snippets WHERE collection_id = ? AND snippet_id = ?`
+
+	result, err := model.DB.Exec(statement, id, snippetID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// MoveSnippet moves snippetID offset places towards the end of collection id,
+// or towards the start if offset is negative, and renumbers the rest. Moves
+// past either end stop there.
+func (model *CollectionModel) MoveSnippet(id int, snippetID int, offset int) error {
+	tx, err := model.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	statement := `SELECT snippet_id FROM collection_snippets WHERE collection_id = ? ORDER BY position FOR UPDATE`
+
+	rows, err := tx.Query(statement, id)
+	if err != nil {
+		return err
+	}
+
+	var order []int
+
+	for rows.Next() {
+		var sid int
+
+		err = rows.Scan(&sid)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		order = append(order, sid)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	from := -1
+	for i, sid := range order {
+		if sid == snippetID {
+			from = i
+			break
+		}
+	}
+
+	if from == -1 {
+		return ErrNoRecord
+	}
+
+	to := min(max(from+offset, 0), len(order)-1)
+	if to == from {
+		return nil
+	}
+
+	order = append(order[:from], order[from+1:]...)
+	order = append(order[:to], append([]int{snippetID}, order[to:]...)...)
+
+	for i, sid := range order {
+		_, err = tx.Exec(`UPDATE collection_snippets SET position = ? WHERE collection_id = ? AND snippet_id = ?`, i+1, id, sid)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type CommentModelInterface interface {
+	Insert(snippetID int, userID int, parentID int, content string) (int, error)
+	Get(id int) (Comment, error)
+	BySnippet(snippetID int) ([]Comment, error)
+	Update(id int, content string) error
+	Delete(id int) error
+}
+
+// Comment is a remark on a snippet. ParentID is zero for top level comments
+// and the id of the comment being replied to otherwise.
+type Comment struct {
+	ID        int
+	SnippetID int
+	UserID    int
+	UserName  string
+	ParentID  int
+	Content   string
+	Created   time.Time
+	Updated   time.Time
+	Deleted   time.Time
+	Replies   []Comment
+}
+
+// commentColumns must be kept in step with scanComment.
+const commentColumns = `c.id, c.snippet_id, c.user_id, u.name, COALESCE(c.parent_id, 0), c.content, c.created, c.updated, c.deleted`
+
+type CommentModel struct {
+	DB *sql.DB
+}
+
+// Insert adds a comment to snippetID. A non-zero parentID must be a comment
+// on the same snippet that hasn't been deleted, or ErrNoRecord is returned.
+func (model *CommentModel) Insert(snippetID int, userID int, parentID int, content string) (int, error) {
+	parent := sql.NullInt64{Int64: int64(parentID), Valid: parentID != 0}
+
+	statement := `INSERT INTO comments (snippet_id, user_id, parent_id, content, created)
+	SELECT ?, ?, ?, ?, UTC_TIMESTAMP() FROM DUAL
+	WHERE ? IS NULL OR EXISTS(SELECT true FROM comments WHERE id = ? AND snippet_id = ? AND deleted IS NULL)`
+
+	result, err := model.DB.Exec(statement, snippetID, userID, parent, content, parent, parent, snippetID)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rows == 0 {
+		return 0, ErrNoRecord
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// Get returns a comment that hasn't been deleted, without its replies.
+func (model *CommentModel) Get(id int) (Comment, error) {
+	statement := `SELECT ` + commentColumns + ` FROM comments c
+	INNER JOIN users u ON u.id = c.user_id
+	WHERE c.deleted IS NULL AND c.id = ?`
+
+	comment, err := scanComment(model.DB.QueryRow(statement, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Comment{}, ErrNoRecord
+		} else {
+			return Comment{}, err
+		}
+	}
+
+	return comment, nil
+}
+
+// BySnippet returns the comments on snippetID as a thread, oldest first.
+func (model *CommentModel) BySnippet(snippetID int) ([]Comment, error) {
+	statement := `SELECT ` + commentColumns + ` FROM comments c
+	INNER JOIN users u ON u.id = c.user_id
+	WHERE c.snippet_id = ? ORDER BY c.id`
+
+	rows, err := model.DB.Query(statement, snippetID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var comments []Comment
+
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return Thread(comments), nil
+}
+
+func (model *CommentModel) Update(id int, content string) error {
+	statement := `UPDATE comments SET content = ?, updated = UTC_TIMESTAMP() WHERE deleted IS NULL AND id = ?`
+
+	_, err := model.DB.Exec(statement, content, id)
+
+	return err
+}
+
+// Delete blanks a comment rather than removing its row so that any replies
+// to it keep their place in the thread.
+func (model *CommentModel) Delete(id int) error {
+	statement := `UPDATE comments SET content = '', deleted = UTC_TIMESTAMP() WHERE deleted IS NULL AND id = ?`
+
+	_, err := model.DB.Exec(statement, id)
+
+	return err
+}
+
+// Thread nests comments, which must be in the order they were posted, under
+// their parents. Deleted comments are dropped unless they have replies that
+// are still shown.
+func Thread(comments []Comment) []Comment {
+	children := map[int][]Comment{}
+
+	for _, comment := range comments {
+		children[comment.ParentID] = append(children[comment.ParentID], comment)
+	}
+
+	var build func(parentID int) []Comment
+
+	build = func(parentID int) []Comment {
+		var thread []Comment
+
+		for _, comment := range children[parentID] {
+			comment.Replies = build(comment.ID)
+
+			if !comment.Deleted.IsZero() && len(comment.Replies) == 0 {
+				continue
+			}
+
+			thread = append(thread, comment)
+		}
+
+		return thread
+	}
+
+	return build(0)
+}
+
+func scanComment(row rowScanner) (Comment, error) {
+	var comment Comment
+	var updated, deleted sql.NullTime
+
+	err := row.Scan(&comment.ID, &comment.SnippetID, &comment.UserID, &comment.UserName, &comment.ParentID, &comment.Content,
+		&comment.Created, &updated, &deleted)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	comment.Updated = updated.Time
+	comment.Deleted = deleted.Time
+
+	return comment, nil
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+// renderThread writes a thread as nested ids, such as "1(2(3)),4".
+func renderThread(thread []Comment) string {
+	var parts []string
+
+	for _, comment := range thread {
+		part := fmt.Sprint(comment.ID)
+		if len(comment.Replies) > 0 {
+			part += "(" + renderThread(comment.Replies) + ")"
+		}
+
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func TestThread(t *testing.T) {
+	deleted := time.Now()
+
+	tests := []struct {
+		name     string
+		comments []Comment
+		want     string
+	}{
+		{
+			name: "Empty",
+			want: "",
+		},
+		{
+			name:     "Top level only",
+			comments: []Comment{{ID: 1}, {ID: 2}},
+			want:     "1,2",
+		},
+		{
+			name:     "Nested replies",
+			comments: []Comment{{ID: 1}, {ID: 2, ParentID: 1}, {ID: 3}, {ID: 4, ParentID: 2}, {ID: 5, ParentID: 1}},
+			want:     "1(2(4),5),3",
+		},
+		{
+			name:     "Deleted without replies",
+			comments: []Comment{{ID: 1}, {ID: 2, ParentID: 1, Deleted: deleted}, {ID: 3, Deleted: deleted}},
+			want:     "1",
+		},
+		{
+			name:     "Deleted with replies",
+			comments: []Comment{{ID: 1, Deleted: deleted}, {ID: 2, ParentID: 1}},
+			want:     "1(2)",
+		},
+		{
+			name:     "Deleted chain",
+			comments: []Comment{{ID: 1, Deleted: deleted}, {ID: 2, ParentID: 1, Deleted: deleted}},
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, renderThread(Thread(tt.comments)), tt.want)
+		})
+	}
+}
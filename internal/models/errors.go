@@ -8,4 +8,7 @@ var (
 	ErrNoRecord           = errors.New("models: no matching record found")
 	ErrInvalidCredentials = errors.New("models: invalid credentials")
 	ErrDuplicateEmail     = errors.New("models: duplicate email")
+	ErrDeleted            = errors.New("models: record has been deleted")
+	ErrBurned             = errors.New("models: record has been burned after reading")
+	ErrDuplicateSnippet   = errors.New("models: snippet already in collection")
 )
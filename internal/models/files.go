@@ -0,0 +1,93 @@
+package models
+
+import (
+	"database/sql"
+)
+
+// File is one of the extra files of a multi-file snippet. A snippet's own
+// Filename, Language and Content make up its first file.
+type File struct {
+	Name     string
+	Language string
+	Content  string
+}
+
+func filesOf(db querier, id int) ([]File, error) {
+	statement := `SELECT name, language, content FROM snippet_files WHERE snippet_id = ? ORDER BY position`
+
+	rows, err := db.Query(statement, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanFiles(rows)
+}
+
+// revisionFilesOf returns the extra files of a snippet as they were at
+// revision id.
+func revisionFilesOf(db querier, id int) ([]File, error) {
+	statement := `SELECT name, language, content FROM revision_files WHERE revision_id = ? ORDER BY position`
+
+	rows, err := db.Query(statement, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanFiles(rows)
+}
+
+func scanFiles(rows *sql.Rows) ([]File, error) {
+	defer rows.Close()
+
+	var files []File
+
+	for rows.Next() {
+		var file File
+
+		err := rows.Scan(&file.Name, &file.Language, &file.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// setFiles replaces the extra files of snippet id.
+func setFiles(tx *sql.Tx, id int, files []File) error {
+	_, err := tx.Exec(`DELETE FROM snippet_files WHERE snippet_id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	statement := `INSERT INTO snippet_files (snippet_id, position, name, language, content) VALUES(?, ?, ?, ?, ?)`
+
+	for i, file := range files {
+		_, err = tx.Exec(statement, id, i+1, file.Name, file.Language, file.Content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setRevisionFiles records files as the extra files of revision id.
+func setRevisionFiles(tx *sql.Tx, id int64, files []File) error {
+	statement := `INSERT INTO revision_files (revision_id, position, name, language, content) VALUES(?, ?, ?, ?, ?)`
+
+	for i, file := range files {
+		_, err := tx.Exec(statement, id, i+1, file.Name, file.Language, file.Content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Tyler-Meador/snippetbox/internal/models"
+)
+
+var mockCollection = models.Collection{
+	ID:       1,
+	UserID:   1,
+	UserName: "Alice",
+	Name:     "Onboarding",
+	Slug:     "onboardingonboardingon",
+	Created:  time.Now(),
+	Snippets: []models.Snippet{mockSnippet, mockPrivateSnippet, mockOtherPrivateSnippet},
+}
+
+var mockOtherCollection = models.Collection{
+	ID:       2,
+	UserID:   2,
+	UserName: "Bob",
+	Name:     "K8s recipes",
+	Slug:     "k8sk8sk8sk8sk8sk8sk8sk",
+	Created:  time.Now(),
+	Snippets: []models.Snippet{mockOtherSnippet},
+}
+
+type CollectionModel struct{}
+
+func (m *CollectionModel) Insert(userID int, name string) (int, error) {
+	return 3, nil
+}
+
+func (m *CollectionModel) Get(slug string) (models.Collection, error) {
+	for _, collection := range []models.Collection{mockCollection, mockOtherCollection} {
+		if collection.Slug == slug {
+			return collection, nil
+		}
+	}
+
+	return models.Collection{}, models.ErrNoRecord
+}
+
+func (m *CollectionModel) ByUser(userID int) ([]models.Collection, error) {
+	switch userID {
+	case 1:
+		return []models.Collection{mockCollection}, nil
+	case 2:
+		return []models.Collection{mockOtherCollection}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (m *CollectionModel) Delete(id int) error {
+	return nil
+}
+
+func (m *CollectionModel) AddSnippet(id int, snippetID int) error {
+	if id == mockCollection.ID && (snippetID == mockSnippet.ID || snippetID == mockPrivateSnippet.ID) {
+		return models.ErrDuplicateSnippet
+	}
+
+	return nil
+}
+
+func (m *CollectionModel) RemoveSnippet(id int, snippetID int) error {
+	if id == mockCollection.ID && (snippetID == mockSnippet.ID || snippetID == mockPrivateSnippet.ID) {
+		return nil
+	}
+
+	return models.ErrNoRecord
+}
+
+func (m *CollectionModel) MoveSnippet(id int, snippetID int, offset int) error {
+	return m.RemoveSnippet(id, snippetID)
+}
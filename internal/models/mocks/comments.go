@@ -0,0 +1,90 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Tyler-Meador/snippetbox/internal/models"
+)
+
+var mockComment = models.Comment{
+	ID:        1,
+	SnippetID: 1,
+	UserID:    1,
+	UserName:  "Alice",
+	Content:   "Should this be a haiku?",
+	Created:   time.Now(),
+}
+
+var mockReply = models.Comment{
+	ID:        2,
+	SnippetID: 1,
+	UserID:    2,
+	UserName:  "Bob",
+	ParentID:  1,
+	Content:   "It already is one.",
+	Created:   time.Now(),
+}
+
+// mockPrivateComment and mockProtectedComment are Alice's comments on Bob's
+// snippets from before he made one private and put a password on the other.
+var mockPrivateComment = models.Comment{
+	ID:        4,
+	SnippetID: 15,
+	UserID:    1,
+	UserName:  "Alice",
+	Content:   "Nice notes.",
+	Created:   time.Now(),
+}
+
+var mockProtectedComment = models.Comment{
+	ID:        5,
+	SnippetID: 7,
+	UserID:    1,
+	UserName:  "Alice",
+	Content:   "Rotate these.",
+	Created:   time.Now(),
+}
+
+type CommentModel struct{}
+
+func (m *CommentModel) Insert(snippetID int, userID int, parentID int, content string) (int, error) {
+	switch {
+	case parentID == 0:
+		return 3, nil
+	case snippetID == mockComment.SnippetID && (parentID == mockComment.ID || parentID == mockReply.ID):
+		return 3, nil
+	default:
+		return 0, models.ErrNoRecord
+	}
+}
+
+func (m *CommentModel) Get(id int) (models.Comment, error) {
+	switch id {
+	case mockComment.ID:
+		return mockComment, nil
+	case mockReply.ID:
+		return mockReply, nil
+	case mockPrivateComment.ID:
+		return mockPrivateComment, nil
+	case mockProtectedComment.ID:
+		return mockProtectedComment, nil
+	default:
+		return models.Comment{}, models.ErrNoRecord
+	}
+}
+
+func (m *CommentModel) BySnippet(snippetID int) ([]models.Comment, error) {
+	if snippetID == mockComment.SnippetID {
+		return models.Thread([]models.Comment{mockComment, mockReply}), nil
+	}
+
+	return nil, nil
+}
+
+func (m *CommentModel) Update(id int, content string) error {
+	return nil
+}
+
+func (m *CommentModel) Delete(id int) error {
+	return nil
+}
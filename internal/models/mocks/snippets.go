@@ -1,34 +1,508 @@
 package mocks
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Tyler-Meador/snippetbox/internal/models"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var mockSnippet = models.Snippet{
-	ID:      1,
-	Title:   "An old silent pond",
-	Content: "An old silent pond...",
-	Created: time.Now(),
-	Expires: time.Now(),
+	ID:         1,
+	UserID:     1,
+	UserName:   "Alice",
+	Forks:      1,
+	Title:      "An old silent pond",
+	Content:    "An old silent pond...",
+	Language:   "plaintext",
+	Visibility: models.VisibilityPublic,
+	Slug:       "aaaaaaaaaaaaaaaaaaaaaa",
+	Created:    time.Now(),
+	Expires:    time.Now(),
+	Tags:       []string{"haiku", "poetry"},
+}
+
+var mockOtherSnippet = models.Snippet{
+	ID:               3,
+	UserID:           2,
+	UserName:         "Bob",
+	ForkedFrom:       1,
+	ForkedFromPublic: true,
+	Stars:            1,
+	Title:            "Over the wintry forest",
+	Content:          "Over the wintry forest, winds howl in rage...",
+	Language:         "go",
+	Visibility:       models.VisibilityPublic,
+	Slug:             "cccccccccccccccccccccc",
+	Created:          time.Now(),
+	Expires:          time.Now(),
+	Tags:             []string{"c#"},
+}
+
+var mockDeletedSnippet = models.Snippet{
+	ID:         4,
+	UserID:     1,
+	UserName:   "Alice",
+	Title:      "A deleted haiku",
+	Content:    "Gone with the spring rain...",
+	Language:   "plaintext",
+	Visibility: models.VisibilityPublic,
+	Slug:       "dddddddddddddddddddddd",
+	Created:    time.Now(),
+	Expires:    time.Now(),
+	Deleted:    time.Now(),
+}
+
+var mockUnlistedSnippet = models.Snippet{
+	ID:         5,
+	UserID:     2,
+	UserName:   "Bob",
+	Title:      "The light of a candle",
+	Content:    "The light of a candle is transferred to another candle...",
+	Language:   "plaintext",
+	Visibility: models.VisibilityUnlisted,
+	Slug:       "unlistedunlistedunlist",
+	Created:    time.Now(),
+	Expires:    time.Now(),
+}
+
+var mockPrivateSnippet = models.Snippet{
+	ID:         6,
+	UserID:     1,
+	UserName:   "Alice",
+	Title:      "A world of dew",
+	Content:    "A world of dew, and within every dewdrop...",
+	Language:   "plaintext",
+	Visibility: models.VisibilityPrivate,
+	Slug:       "privateprivateprivatep",
+	Created:    time.Now(),
+	Expires:    time.Now(),
+}
+
+// mockOtherPrivateSnippet was made private after Alice collected it.
+var mockOtherPrivateSnippet = models.Snippet{
+	ID:         15,
+	UserID:     2,
+	UserName:   "Bob",
+	Title:      "Bob's private notes",
+	Content:    "Nothing to see here",
+	Language:   "plaintext",
+	Visibility: models.VisibilityPrivate,
+	Slug:       "otherprivateotherpriva",
+	Created:    time.Now(),
+	Expires:    time.Now(),
+}
+
+var mockProtectedSnippet = models.Snippet{
+	ID:             7,
+	UserID:         2,
+	UserName:       "Bob",
+	Title:          "Staging credentials",
+	Content:        "DB_PASSWORD=hunter2",
+	Language:       "ini",
+	Visibility:     models.VisibilityPublic,
+	Slug:           "protectedprotectedprot",
+	HashedPassword: mustHash("open sesame"),
+	Created:        time.Now(),
+	Expires:        time.Now(),
+}
+
+// mockProtectedFork is what forking mockProtectedSnippet creates.
+var mockProtectedFork = models.Snippet{
+	ID:               14,
+	UserID:           1,
+	UserName:         "Alice",
+	ForkedFrom:       7,
+	ForkedFromPublic: true,
+	Title:            "Staging credentials",
+	Content:          "DB_PASSWORD=hunter2",
+	Language:         "ini",
+	Visibility:       models.VisibilityPublic,
+	Slug:             "protectedforkprotected",
+	HashedPassword:   mockProtectedSnippet.HashedPassword,
+	Created:          time.Now(),
+	Expires:          time.Now(),
+}
+
+var mockBurnSnippet = models.Snippet{
+	ID:               8,
+	UserID:           2,
+	UserName:         "Bob",
+	Title:            "One-time token",
+	Content:          "token: 5f4dcc3b5aa765d61d8327deb882cf99",
+	Language:         "yaml",
+	Visibility:       models.VisibilityUnlisted,
+	Slug:             "burnburnburnburnburnbu",
+	BurnAfterReading: true,
+	Created:          time.Now(),
+	Expires:          time.Now(),
+}
+
+// mockPublicBurnSnippet is public, but as it burns after reading it should
+// never be listed.
+var mockPublicBurnSnippet = models.Snippet{
+	ID:               13,
+	UserID:           2,
+	UserName:         "Bob",
+	Title:            "A silent secret",
+	Content:          "A silent secret: the pond is empty",
+	Language:         "plaintext",
+	Visibility:       models.VisibilityPublic,
+	Slug:             "publicburnpublicburnpu",
+	BurnAfterReading: true,
+	Created:          time.Now(),
+	Expires:          time.Now(),
+	Tags:             []string{"haiku"},
+}
+
+var mockBurnedSnippet = models.Snippet{
+	ID:               9,
+	UserID:           2,
+	UserName:         "Bob",
+	Visibility:       models.VisibilityUnlisted,
+	Slug:             "burnedburnedburnedburn",
+	BurnAfterReading: true,
+	Created:          time.Now(),
+	Expires:          time.Now(),
+	Deleted:          time.Now(),
+	Burned:           time.Now(),
+}
+
+var mockBundleSnippet = models.Snippet{
+	ID:         10,
+	UserID:     1,
+	UserName:   "Alice",
+	ForkedFrom: 5,
+	Title:      "Deploy bundle",
+	Content:    "FROM golang:1.22",
+	Filename:   "Dockerfile",
+	Language:   "dockerfile",
+	Visibility: models.VisibilityPublic,
+	Slug:       "bundlebundlebundlebund",
+	Created:    time.Now(),
+	Expires:    time.Now(),
+	Files: []models.File{
+		{Name: "compose.yaml", Language: "yaml", Content: "services:\n  web:\n    build: ."},
+		{Name: "deploy.sh", Language: "bash", Content: "docker compose up -d"},
+	},
+}
+
+// mockLargeSnippet and mockOtherLargeSnippet are long and share no lines, so
+// comparing them is too large a diff to show.
+var mockLargeSnippet = models.Snippet{
+	ID:         11,
+	UserID:     1,
+	UserName:   "Alice",
+	Title:      "Large log",
+	Content:    numberedLines("request", 8000),
+	Language:   "plaintext",
+	Visibility: models.VisibilityPublic,
+	Slug:       "largelargelargelargela",
+	Created:    time.Now(),
+	Expires:    time.Now(),
+}
+
+var mockOtherLargeSnippet = models.Snippet{
+	ID:         12,
+	UserID:     2,
+	UserName:   "Bob",
+	Title:      "Other large log",
+	Content:    numberedLines("response", 8000),
+	Language:   "plaintext",
+	Visibility: models.VisibilityPublic,
+	Slug:       "otherlargeotherlargeot",
+	Created:    time.Now(),
+	Expires:    time.Now(),
+}
+
+var mockSnippets = []models.Snippet{mockSnippet, mockOtherSnippet, mockUnlistedSnippet, mockPrivateSnippet, mockProtectedSnippet, mockBurnSnippet, mockBundleSnippet, mockLargeSnippet, mockOtherLargeSnippet, mockPublicBurnSnippet, mockProtectedFork, mockOtherPrivateSnippet}
+
+// listed drops the snippets that Latest and Tagged in models leave out.
+// Search also leaves out protected snippets, since its excerpts show their
+// content.
+func listed(snippets ...models.Snippet) []models.Snippet {
+	return slices.DeleteFunc(snippets, func(snip models.Snippet) bool {
+		return snip.Visibility != models.VisibilityPublic || snip.BurnAfterReading
+	})
+}
+
+func numberedLines(prefix string, n int) string {
+	var builder strings.Builder
+
+	for i := range n {
+		fmt.Fprintf(&builder, "%s %d\n", prefix, i)
+	}
+
+	return builder.String()
+}
+
+func mustHash(password string) []byte {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		panic(err)
+	}
+
+	return hashedPassword
 }
 
 type SnippetModel struct{}
 
-func (m *SnippetModel) Insert(title string, content string, expires int) (int, error) {
+func (m *SnippetModel) Insert(userID int, input models.SnippetInput) (int, error) {
 	return 2, nil
 }
 
 func (m *SnippetModel) Get(id int) (models.Snippet, error) {
+	if id == mockDeletedSnippet.ID {
+		return mockDeletedSnippet, models.ErrDeleted
+	}
+
+	if id == mockBurnedSnippet.ID {
+		return mockBurnedSnippet, models.ErrBurned
+	}
+
+	for _, snip := range mockSnippets {
+		if snip.ID == id {
+			return snip, nil
+		}
+	}
+
+	return models.Snippet{}, models.ErrNoRecord
+}
+
+func (m *SnippetModel) GetBySlug(slug string) (models.Snippet, error) {
+	if slug == mockDeletedSnippet.Slug {
+		return mockDeletedSnippet, models.ErrDeleted
+	}
+
+	if slug == mockBurnedSnippet.Slug {
+		return mockBurnedSnippet, models.ErrBurned
+	}
+
+	for _, snip := range mockSnippets {
+		if snip.Slug == slug {
+			return snip, nil
+		}
+	}
+
+	return models.Snippet{}, models.ErrNoRecord
+}
+
+var mockRevision = models.Revision{
+	ID:        1,
+	SnippetID: 1,
+	UserID:    1,
+	UserName:  "Alice",
+	Title:     "An old pond",
+	Content:   "An old pond...",
+	Language:  "plaintext",
+	Created:   time.Now(),
+}
+
+// mockBundleRevision is mockBundleSnippet from before deploy.sh was added.
+var mockBundleRevision = models.Revision{
+	ID:        2,
+	SnippetID: 10,
+	UserID:    1,
+	UserName:  "Alice",
+	Title:     "Deploy bundle",
+	Content:   "FROM golang:1.22",
+	Filename:  "Dockerfile",
+	Language:  "dockerfile",
+	Files: []models.File{
+		{Name: "compose.yaml", Language: "yaml", Content: "services:\n  web:\n    build: ."},
+	},
+	Created: time.Now(),
+}
+
+func (m *SnippetModel) Update(id int, userID int, input models.SnippetInput) error {
+	for _, snip := range mockSnippets {
+		if snip.ID == id {
+			return nil
+		}
+	}
+
+	return models.ErrNoRecord
+}
+
+func (m *SnippetModel) Latest(cursor models.Cursor) (models.SnippetPage, error) {
+	return page(listed(mockPublicBurnSnippet, mockOtherSnippet, mockSnippet), cursor), nil
+}
+
+func (m *SnippetModel) ByUser(userID int, cursor models.Cursor) (models.SnippetPage, error) {
+	if userID == mockSnippet.UserID {
+		return page([]models.Snippet{mockSnippet}, cursor), nil
+	}
+
+	return models.SnippetPage{}, nil
+}
+
+func (m *SnippetModel) Revisions(id int) ([]models.Revision, error) {
 	switch id {
-	case 1:
-		return mockSnippet, nil
-	default:
-		return models.Snippet{}, models.ErrNoRecord
+	case mockSnippet.ID:
+		return []models.Revision{mockRevision}, nil
+	case mockBundleSnippet.ID:
+		return []models.Revision{mockBundleRevision}, nil
+	}
+
+	return nil, nil
+}
+
+func (m *SnippetModel) GetRevision(id int, revisionID int) (models.Revision, error) {
+	for _, revision := range []models.Revision{mockRevision, mockBundleRevision} {
+		if id == revision.SnippetID && revisionID == revision.ID {
+			return revision, nil
+		}
+	}
+
+	return models.Revision{}, models.ErrNoRecord
+}
+
+func (m *SnippetModel) Delete(id int) error {
+	return nil
+}
+
+func (m *SnippetModel) Burn(id int) (models.Snippet, error) {
+	if id == mockBurnSnippet.ID {
+		return mockBurnSnippet, nil
+	}
+
+	return models.Snippet{}, models.ErrBurned
+}
+
+func (m *SnippetModel) Fork(id int, userID int) (int, error) {
+	if id == mockProtectedSnippet.ID {
+		return mockProtectedFork.ID, nil
 	}
+
+	for _, snip := range mockSnippets {
+		if snip.ID == id {
+			return 2, nil
+		}
+	}
+
+	return 0, models.ErrNoRecord
+}
+
+func (m *SnippetModel) Star(id int, userID int) error {
+	for _, snip := range mockSnippets {
+		if snip.ID == id {
+			return nil
+		}
+	}
+
+	return models.ErrNoRecord
+}
+
+func (m *SnippetModel) Unstar(id int, userID int) error {
+	return m.Star(id, userID)
 }
 
-func (m *SnippetModel) Latest() ([]models.Snippet, error) {
-	return []models.Snippet{mockSnippet}, nil
+// IsStarred reports Alice as having starred Bob's public snippet.
+func (m *SnippetModel) IsStarred(id int, userID int) (bool, error) {
+	return id == mockOtherSnippet.ID && userID == mockSnippet.UserID, nil
+}
+
+func (m *SnippetModel) Starred(userID int, cursor models.Cursor) (models.SnippetPage, error) {
+	if userID == mockSnippet.UserID {
+		return page([]models.Snippet{mockOtherSnippet}, cursor), nil
+	}
+
+	return models.SnippetPage{}, nil
+}
+
+func (m *SnippetModel) Restore(id int, userID int) error {
+	if id == mockDeletedSnippet.ID && userID == mockDeletedSnippet.UserID {
+		return nil
+	}
+
+	return models.ErrNoRecord
+}
+
+func (m *SnippetModel) Trash(userID int) ([]models.Snippet, error) {
+	if userID == mockDeletedSnippet.UserID {
+		return []models.Snippet{mockDeletedSnippet}, nil
+	}
+
+	return nil, nil
+}
+
+func (m *SnippetModel) PurgeTrash(limit int) (int64, error) {
+	return 0, nil
+}
+
+func (m *SnippetModel) DeleteExpired(limit int) (int64, error) {
+	return 0, nil
+}
+
+func (m *SnippetModel) Search(query string, page int, size int) (models.SearchPage, error) {
+	result := models.SearchPage{Page: page}
+
+	for _, snip := range listed(mockPublicBurnSnippet, mockProtectedSnippet, mockSnippet) {
+		if !snip.Protected() && strings.Contains(strings.ToLower(snip.Content), strings.ToLower(query)) {
+			result.Results = append(result.Results, models.SearchResult{
+				Snippet: snip,
+				Score:   1,
+				Excerpt: models.Excerpt(snip.Content, strings.Fields(query)),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func (m *SnippetModel) Tagged(tag string, cursor models.Cursor) (models.SnippetPage, error) {
+	var snippets []models.Snippet
+
+	for _, snip := range listed(mockPublicBurnSnippet, mockSnippet) {
+		if slices.Contains(snip.Tags, tag) {
+			snippets = append(snippets, snip)
+		}
+	}
+
+	if snippets == nil {
+		return models.SnippetPage{}, nil
+	}
+
+	return page(snippets, cursor), nil
+}
+
+// page slices snippets, which must be ordered newest first, the same way the
+// keyset queries in models do.
+func page(snippets []models.Snippet, cursor models.Cursor) models.SnippetPage {
+	size := cursor.Size
+	if size < 1 {
+		size = models.DefaultPageSize
+	}
+
+	start, end := 0, len(snippets)
+
+	switch {
+	case cursor.After > 0:
+		for end > 0 && snippets[end-1].ID <= cursor.After {
+			end--
+		}
+		start = max(0, end-size)
+	case cursor.Before > 0:
+		for start < len(snippets) && snippets[start].ID >= cursor.Before {
+			start++
+		}
+		end = min(len(snippets), start+size)
+	default:
+		end = min(len(snippets), size)
+	}
+
+	result := models.SnippetPage{Snippets: snippets[start:end]}
+
+	if start > 0 && start < end {
+		result.Previous = snippets[start].ID
+	}
+
+	if end < len(snippets) && start < end {
+		result.Next = snippets[end-1].ID
+	}
+
+	return result
 }
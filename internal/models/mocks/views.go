@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Tyler-Meador/snippetbox/internal/models"
+)
+
+type ViewModel struct{}
+
+func (m *ViewModel) Add(counts []models.ViewCount) error {
+	return nil
+}
+
+func (m *ViewModel) Stats(snippetID int, days int) (models.ViewStats, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	stats := models.ViewStats{Views: 12, Uniques: 7}
+
+	for i := days - 1; i >= 0; i-- {
+		stats.Days = append(stats.Days, models.DailyViews{Day: today.AddDate(0, 0, -i)})
+	}
+
+	stats.Days[len(stats.Days)-1].Views = 4
+	stats.Days[len(stats.Days)-1].Uniques = 3
+
+	stats.Referrers = []models.ReferrerCount{{Referrer: "example.com", Views: 3}, {Referrer: "", Views: 1}}
+
+	return stats, nil
+}
@@ -0,0 +1,79 @@
+package models
+
+const DefaultPageSize = 10
+
+// Cursor selects one page of a listing ordered newest first. Before and After
+// are exclusive snippet id bounds; when both are zero the first page is
+// returned.
+type Cursor struct {
+	Before int
+	After  int
+	Size   int
+}
+
+// SnippetPage is one page of a listing. Next is the Before cursor of the
+// following (older) page and Previous the After cursor of the preceding
+// (newer) page; each is zero when there is no such page.
+type SnippetPage struct {
+	Snippets []Snippet
+	Next     int
+	Previous int
+}
+
+func (cursor Cursor) size() int {
+	if cursor.Size < 1 {
+		return DefaultPageSize
+	}
+
+	return cursor.Size
+}
+
+// clause returns the keyset condition, ordering and limit to append to a
+// snippet query. One extra row is fetched so the caller can tell whether
+// another page follows in the direction of travel.
+func (cursor Cursor) clause() (string, []any) {
+	switch {
+	case cursor.After > 0:
+		return ` AND s.id > ? ORDER BY s.id ASC LIMIT ?`, []any{cursor.After, cursor.size() + 1}
+	case cursor.Before > 0:
+		return ` AND s.id < ? ORDER BY s.id DESC LIMIT ?`, []any{cursor.Before, cursor.size() + 1}
+	default:
+		return ` ORDER BY s.id DESC LIMIT ?`, []any{cursor.size() + 1}
+	}
+}
+
+func (cursor Cursor) page(snippets []Snippet) SnippetPage {
+	more := len(snippets) > cursor.size()
+	if more {
+		snippets = snippets[:cursor.size()]
+	}
+
+	if cursor.After > 0 {
+		for i, j := 0, len(snippets)-1; i < j; i, j = i+1, j-1 {
+			snippets[i], snippets[j] = snippets[j], snippets[i]
+		}
+	}
+
+	page := SnippetPage{Snippets: snippets}
+	if len(snippets) == 0 {
+		return page
+	}
+
+	first, last := snippets[0].ID, snippets[len(snippets)-1].ID
+
+	if cursor.After > 0 {
+		page.Next = last
+		if more {
+			page.Previous = first
+		}
+	} else {
+		if more {
+			page.Next = last
+		}
+		if cursor.Before > 0 {
+			page.Previous = first
+		}
+	}
+
+	return page
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+func snippetsWithIDs(ids ...int) []Snippet {
+	var snippets []Snippet
+
+	for _, id := range ids {
+		snippets = append(snippets, Snippet{ID: id})
+	}
+
+	return snippets
+}
+
+func TestCursorPage(t *testing.T) {
+	tests := []struct {
+		name         string
+		cursor       Cursor
+		rows         []Snippet
+		wantFirst    int
+		wantCount    int
+		wantNext     int
+		wantPrevious int
+	}{
+		{
+			name:      "First page with more",
+			cursor:    Cursor{Size: 2},
+			rows:      snippetsWithIDs(9, 8, 7),
+			wantFirst: 9,
+			wantCount: 2,
+			wantNext:  8,
+		},
+		{
+			name:      "Only page",
+			cursor:    Cursor{Size: 2},
+			rows:      snippetsWithIDs(9, 8),
+			wantFirst: 9,
+			wantCount: 2,
+		},
+		{
+			name:         "Before cursor, last page",
+			cursor:       Cursor{Before: 8, Size: 2},
+			rows:         snippetsWithIDs(7),
+			wantFirst:    7,
+			wantCount:    1,
+			wantPrevious: 7,
+		},
+		{
+			name:         "After cursor with more",
+			cursor:       Cursor{After: 5, Size: 2},
+			rows:         snippetsWithIDs(6, 7, 8),
+			wantFirst:    7,
+			wantCount:    2,
+			wantNext:     6,
+			wantPrevious: 7,
+		},
+		{
+			name:      "After cursor, first page",
+			cursor:    Cursor{After: 5, Size: 2},
+			rows:      snippetsWithIDs(6, 7),
+			wantFirst: 7,
+			wantCount: 2,
+			wantNext:  6,
+		},
+		{
+			name:      "Default size",
+			cursor:    Cursor{},
+			rows:      snippetsWithIDs(12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2),
+			wantFirst: 12,
+			wantCount: DefaultPageSize,
+			wantNext:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := tt.cursor.page(tt.rows)
+
+			assert.Equal(t, len(page.Snippets), tt.wantCount)
+			assert.Equal(t, page.Snippets[0].ID, tt.wantFirst)
+			assert.Equal(t, page.Next, tt.wantNext)
+			assert.Equal(t, page.Previous, tt.wantPrevious)
+		})
+	}
+}
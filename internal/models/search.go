@@ -0,0 +1,197 @@
+package models
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const excerptLength = 240
+
+type SearchResult struct {
+	Snippet
+	Score   float64
+	Excerpt []ExcerptPart
+}
+
+// ExcerptPart is a run of text from a search excerpt; Match is set on runs
+// that matched one of the search terms.
+type ExcerptPart struct {
+	Text  string
+	Match bool
+}
+
+// SearchPage is one page of ranked results. Next and Previous are page
+// numbers, zero when there is no such page.
+type SearchPage struct {
+	Results  []SearchResult
+	Page     int
+	Next     int
+	Previous int
+}
+
+func (model *SnippetModel) Search(query string, page int, size int) (SearchPage, error) {
+	if size < 1 {
+		size = DefaultPageSize
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	// A snippet's score adds its best matching extra file to the match on
+	// its title and first file.
+	statement := `SELECT ` + snippetColumns + `,
+	MATCH(s.title, s.content) AGAINST(? IN NATURAL LANGUAGE MODE) +
+	COALESCE((SELECT MAX(MATCH(f.name, f.content) AGAINST(? IN NATURAL LANGUAGE MODE)) FROM snippet_files f WHERE f.snippet_id = s.id), 0) AS score
+	FROM snippets s
+	INNER JOIN users u ON u.id = s.user_id
+	WHERE (MATCH(s.title, s.content) AGAINST(? IN NATURAL LANGUAGE MODE)
+	OR EXISTS(SELECT 1 FROM snippet_files f WHERE f.snippet_id = s.id AND MATCH(f.name, f.content) AGAINST(? IN NATURAL LANGUAGE MODE)))
+	AND (s.expires IS NULL OR s.expires > UTC_TIMESTAMP()) AND s.deleted IS NULL AND s.visibility = 'public' AND s.hashed_password IS NULL AND s.burn_after_reading = FALSE
+	ORDER BY score DESC, s.id DESC LIMIT ? OFFSET ?`
+
+	rows, err := model.DB.Query(statement, query, query, query, query, size+1, (page-1)*size)
+	if err != nil {
+		return SearchPage{}, err
+	}
+
+	defer rows.Close()
+
+	result := SearchPage{Page: page}
+
+	for rows.Next() {
+		var score float64
+
+		snip, err := scanSnippet(rows, &score)
+		if err != nil {
+			return SearchPage{}, err
+		}
+
+		result.Results = append(result.Results, SearchResult{Snippet: snip, Score: score})
+	}
+
+	if err = rows.Err(); err != nil {
+		return SearchPage{}, err
+	}
+
+	rows.Close()
+
+	if len(result.Results) > size {
+		result.Results = result.Results[:size]
+		result.Next = page + 1
+	}
+
+	terms := searchTerms(query)
+
+	// The excerpt comes from whichever file matched, so the files are only
+	// loaded once the rows have been read.
+	for i := range result.Results {
+		found := &result.Results[i]
+
+		found.Files, err = filesOf(model.DB, found.ID)
+		if err != nil {
+			return SearchPage{}, err
+		}
+
+		found.Excerpt = excerptOf(found.Snippet, terms)
+	}
+
+	if page > 1 {
+		result.Previous = page - 1
+	}
+
+	return result, nil
+}
+
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(query, func(r rune) bool {
+		return !(r == '_' || r == '-' || r == '.' || r == '#' || r == '+' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r >= utf8.RuneSelf)
+	})
+}
+
+// excerptOf returns the excerpt of the first of snip's files that contains
+// one of the terms, or of its first file if none do.
+func excerptOf(snip Snippet, terms []string) []ExcerptPart {
+	excerpt := Excerpt(snip.Content, terms)
+
+	if slices.ContainsFunc(excerpt, func(part ExcerptPart) bool { return part.Match }) {
+		return excerpt
+	}
+
+	for _, file := range snip.Files {
+		fileExcerpt := Excerpt(file.Content, terms)
+
+		if slices.ContainsFunc(fileExcerpt, func(part ExcerptPart) bool { return part.Match }) {
+			return fileExcerpt
+		}
+	}
+
+	return excerpt
+}
+
+// Excerpt cuts a window of content around the first occurrence of any of the
+// terms and splits it so that every occurrence inside the window can be
+// highlighted. Matching is case-insensitive.
+func Excerpt(content string, terms []string) []ExcerptPart {
+	var patterns []string
+	for _, term := range terms {
+		patterns = append(patterns, regexp.QuoteMeta(term))
+	}
+
+	var matches [][]int
+	if len(patterns) > 0 {
+		rx := regexp.MustCompile("(?i)" + strings.Join(patterns, "|"))
+		matches = rx.FindAllStringIndex(content, -1)
+	}
+
+	start := 0
+	if len(matches) > 0 {
+		start = max(0, matches[0][0]-excerptLength/4)
+	}
+
+	end := min(len(content), start+excerptLength)
+
+	for start > 0 && !utf8.RuneStart(content[start]) {
+		start--
+	}
+
+	for end < len(content) && !utf8.RuneStart(content[end]) {
+		end++
+	}
+
+	var parts []ExcerptPart
+
+	if start > 0 {
+		parts = append(parts, ExcerptPart{Text: "…"})
+	}
+
+	position := start
+	for _, match := range matches {
+		if match[0] < position {
+			continue
+		}
+
+		if match[1] > end {
+			break
+		}
+
+		if match[0] > position {
+			parts = append(parts, ExcerptPart{Text: content[position:match[0]]})
+		}
+
+		parts = append(parts, ExcerptPart{Text: content[match[0]:match[1]], Match: true})
+		position = match[1]
+	}
+
+	if position < end {
+		parts = append(parts, ExcerptPart{Text: content[position:end]})
+	}
+
+	if end < len(content) {
+		parts = append(parts, ExcerptPart{Text: "…"})
+	}
+
+	return parts
+}
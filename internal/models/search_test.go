@@ -0,0 +1,120 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+func renderExcerpt(parts []ExcerptPart) string {
+	var builder strings.Builder
+
+	for _, part := range parts {
+		if part.Match {
+			builder.WriteString("[" + part.Text + "]")
+		} else {
+			builder.WriteString(part.Text)
+		}
+	}
+
+	return builder.String()
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("x", 300)
+
+	tests := []struct {
+		name    string
+		content string
+		terms   []string
+		want    string
+	}{
+		{
+			name:    "Single match",
+			content: "An old silent pond",
+			terms:   []string{"silent"},
+			want:    "An old [silent] pond",
+		},
+		{
+			name:    "Case insensitive",
+			content: "An old Silent pond, silent still",
+			terms:   []string{"SILENT"},
+			want:    "An old [Silent] pond, [silent] still",
+		},
+		{
+			name:    "Several terms",
+			content: "A frog jumps into the pond",
+			terms:   []string{"frog", "pond"},
+			want:    "A [frog] jumps into the [pond]",
+		},
+		{
+			name:    "No match",
+			content: "An old silent pond",
+			terms:   []string{"frog"},
+			want:    "An old silent pond",
+		},
+		{
+			name:    "Regexp characters",
+			content: "c++ and c#",
+			terms:   []string{"c++"},
+			want:    "[c++] and c#",
+		},
+		{
+			name:    "Window around late match",
+			content: long + " splash " + long,
+			terms:   []string{"splash"},
+			want:    "…" + strings.Repeat("x", 59) + " [splash] " + strings.Repeat("x", 173) + "…",
+		},
+		{
+			name:    "Multibyte boundary",
+			content: strings.Repeat("é", 200) + " pond",
+			terms:   []string{"pond"},
+			want:    "…" + strings.Repeat("é", 30) + " [pond]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, renderExcerpt(Excerpt(tt.content, tt.terms)), tt.want)
+		})
+	}
+}
+
+func TestExcerptOf(t *testing.T) {
+	snip := Snippet{
+		Content: "FROM golang:1.22",
+		Files: []File{
+			{Name: "compose.yaml", Content: "services:\n  web:"},
+			{Name: "deploy.sh", Content: "docker compose up -d"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		terms []string
+		want  string
+	}{
+		{
+			name:  "First file",
+			terms: []string{"golang"},
+			want:  "FROM [golang]:1.22",
+		},
+		{
+			name:  "Extra file",
+			terms: []string{"compose"},
+			want:  "docker [compose] up -d",
+		},
+		{
+			name:  "No match",
+			terms: []string{"kubectl"},
+			want:  "FROM golang:1.22",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, renderExcerpt(excerptOf(snip, tt.terms)), tt.want)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"database/sql"
+)
+
+type SessionModelInterface interface {
+	DeleteExpired(limit int) (int64, error)
+}
+
+// SessionModel works on the sessions table that the scs mysqlstore keeps
+// its sessions in.
+type SessionModel struct {
+	DB *sql.DB
+}
+
+// DeleteExpired deletes up to limit sessions that have expired.
+func (model *SessionModel) DeleteExpired(limit int) (int64, error) {
+	statement := `DELETE FROM sessions WHERE expiry < UTC_TIMESTAMP(6) LIMIT ?`
+
+	result, err := model.DB.Exec(statement, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
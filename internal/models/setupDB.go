@@ -25,13 +25,39 @@ func NewDB(sqlUser, sqlPass string) error {
 		return err
 	}
 
+	_, err = db.Exec(`
+		CREATE TABLE users (
+			id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+			name VARCHAR(255) NOT NULL,
+			email VARCHAR(255) NOT NULL,
+			hashed_password CHAR(60) NOT NULL,
+			created DATETIME NOT NULL
+		);`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
 	_, err = db.Exec(`
 		CREATE TABLE snippets (
 			id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+			user_id INTEGER NOT NULL,
+			forked_from INTEGER NULL,
 			title VARCHAR(100) NOT NULL,
 			content TEXT NOT NULL,
+			filename VARCHAR(100) NOT NULL DEFAULT '',
+			language VARCHAR(30) NOT NULL DEFAULT 'plaintext',
+			visibility ENUM('public', 'unlisted', 'private') NOT NULL DEFAULT 'public',
+			slug CHAR(22) NULL,
+			hashed_password CHAR(60) NULL,
+			burn_after_reading BOOLEAN NOT NULL DEFAULT FALSE,
 			created DATETIME NOT NULL,
-			expires DATETIME NOT NULL
+			expires DATETIME NULL,
+			deleted DATETIME NULL,
+			burned DATETIME NULL,
+			CONSTRAINT snippets_uc_slug UNIQUE (slug),
+			CONSTRAINT fk_snippets_user FOREIGN KEY (user_id) REFERENCES users(id),
+			CONSTRAINT fk_snippets_forked_from FOREIGN KEY (forked_from) REFERENCES snippets(id) ON DELETE SET NULL
 		);
 	`)
 	if err != nil {
@@ -45,14 +71,194 @@ func NewDB(sqlUser, sqlPass string) error {
 		return err
 	}
 
+	_, err = db.Exec("CREATE INDEX idx_snippets_expires ON snippets(expires)")
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec("CREATE FULLTEXT INDEX idx_snippets_search ON snippets(title, content)")
+	if err != nil {
+		db.Close()
+		return err
+	}
+
 	_, err = db.Exec(`
-		CREATE TABLE users (
+		CREATE TABLE snippet_revisions (
 			id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
-			name VARCHAR(255) NOT NULL,
-			email VARCHAR(255) NOT NULL,
-			hashed_password CHAR(60) NOT NULL,
-			created DATETIME NOT NULL
-		);`)
+			snippet_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			title VARCHAR(100) NOT NULL,
+			content TEXT NOT NULL,
+			filename VARCHAR(100) NOT NULL DEFAULT '',
+			language VARCHAR(30) NOT NULL DEFAULT 'plaintext',
+			created DATETIME NOT NULL,
+			CONSTRAINT fk_snippet_revisions_snippet FOREIGN KEY (snippet_id) REFERENCES snippets(id) ON DELETE CASCADE,
+			CONSTRAINT fk_snippet_revisions_user FOREIGN KEY (user_id) REFERENCES users(id)
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE snippet_files (
+			id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+			snippet_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			language VARCHAR(30) NOT NULL DEFAULT 'plaintext',
+			content TEXT NOT NULL,
+			CONSTRAINT snippet_files_uc_position UNIQUE (snippet_id, position),
+			CONSTRAINT fk_snippet_files_snippet FOREIGN KEY (snippet_id) REFERENCES snippets(id) ON DELETE CASCADE
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec("CREATE FULLTEXT INDEX idx_snippet_files_search ON snippet_files(name, content)")
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE revision_files (
+			id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+			revision_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			language VARCHAR(30) NOT NULL DEFAULT 'plaintext',
+			content TEXT NOT NULL,
+			CONSTRAINT revision_files_uc_position UNIQUE (revision_id, position),
+			CONSTRAINT fk_revision_files_revision FOREIGN KEY (revision_id) REFERENCES snippet_revisions(id) ON DELETE CASCADE
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE tags (
+			id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+			name VARCHAR(30) NOT NULL,
+			CONSTRAINT tags_uc_name UNIQUE (name)
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE snippet_tags (
+			snippet_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			PRIMARY KEY (snippet_id, tag_id),
+			CONSTRAINT fk_snippet_tags_snippet FOREIGN KEY (snippet_id) REFERENCES snippets(id) ON DELETE CASCADE,
+			CONSTRAINT fk_snippet_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE collections (
+			id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+			user_id INTEGER NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			slug CHAR(22) NOT NULL,
+			created DATETIME NOT NULL,
+			CONSTRAINT collections_uc_slug UNIQUE (slug),
+			CONSTRAINT fk_collections_user FOREIGN KEY (user_id) REFERENCES users(id)
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE collection_snippets (
+			collection_id INTEGER NOT NULL,
+			snippet_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			PRIMARY KEY (collection_id, snippet_id),
+			CONSTRAINT fk_collection_snippets_collection FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE CASCADE,
+			CONSTRAINT fk_collection_snippets_snippet FOREIGN KEY (snippet_id) REFERENCES snippets(id) ON DELETE CASCADE
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE stars (
+			user_id INTEGER NOT NULL,
+			snippet_id INTEGER NOT NULL,
+			created DATETIME NOT NULL,
+			PRIMARY KEY (user_id, snippet_id),
+			CONSTRAINT fk_stars_user FOREIGN KEY (user_id) REFERENCES users(id),
+			CONSTRAINT fk_stars_snippet FOREIGN KEY (snippet_id) REFERENCES snippets(id) ON DELETE CASCADE
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE comments (
+			id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+			snippet_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			parent_id INTEGER NULL,
+			content TEXT NOT NULL,
+			created DATETIME NOT NULL,
+			updated DATETIME NULL,
+			deleted DATETIME NULL,
+			CONSTRAINT fk_comments_snippet FOREIGN KEY (snippet_id) REFERENCES snippets(id) ON DELETE CASCADE,
+			CONSTRAINT fk_comments_user FOREIGN KEY (user_id) REFERENCES users(id),
+			CONSTRAINT fk_comments_parent FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE snippet_views (
+			snippet_id INTEGER NOT NULL,
+			day DATE NOT NULL,
+			views INTEGER NOT NULL,
+			uniques INTEGER NOT NULL,
+			PRIMARY KEY (snippet_id, day),
+			CONSTRAINT fk_snippet_views_snippet FOREIGN KEY (snippet_id) REFERENCES snippets(id) ON DELETE CASCADE
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE snippet_referrers (
+			snippet_id INTEGER NOT NULL,
+			day DATE NOT NULL,
+			referrer VARCHAR(255) NOT NULL,
+			views INTEGER NOT NULL,
+			PRIMARY KEY (snippet_id, day, referrer),
+			CONSTRAINT fk_snippet_referrers_snippet FOREIGN KEY (snippet_id) REFERENCES snippets(id) ON DELETE CASCADE
+		);
+	`)
 	if err != nil {
 		db.Close()
 		return err
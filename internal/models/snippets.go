@@ -1,34 +1,175 @@
 package models
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/base64"
 	"errors"
+	"slices"
+	"strconv"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 type SnippetModelInterface interface {
-	Insert(title string, content string, expires int) (int, error)
+	Insert(userID int, input SnippetInput) (int, error)
 	Get(id int) (Snippet, error)
-	Latest() ([]Snippet, error)
+	GetBySlug(slug string) (Snippet, error)
+	Update(id int, userID int, input SnippetInput) error
+	Revisions(id int) ([]Revision, error)
+	GetRevision(id int, revisionID int) (Revision, error)
+	Latest(cursor Cursor) (SnippetPage, error)
+	ByUser(userID int, cursor Cursor) (SnippetPage, error)
+	Delete(id int) error
+	Burn(id int) (Snippet, error)
+	Fork(id int, userID int) (int, error)
+	Star(id int, userID int) error
+	Unstar(id int, userID int) error
+	IsStarred(id int, userID int) (bool, error)
+	Starred(userID int, cursor Cursor) (SnippetPage, error)
+	Restore(id int, userID int) error
+	Trash(userID int) ([]Snippet, error)
+	PurgeTrash(limit int) (int64, error)
+	DeleteExpired(limit int) (int64, error)
+	Search(query string, page int, size int) (SearchPage, error)
+	Tagged(tag string, cursor Cursor) (SnippetPage, error)
 }
 
+// TrashRetentionDays is how long a deleted snippet can be restored before it
+// is purged for good.
+const TrashRetentionDays = 30
+
+// snippetColumns must be kept in step with scanSnippet.
+const snippetColumns = `s.id, s.user_id, u.name, COALESCE(s.forked_from, 0), s.title, s.content, s.filename, s.language, s.visibility, COALESCE(s.slug, ''),
+	COALESCE(s.hashed_password, ''), s.burn_after_reading, s.created, s.expires, s.deleted, s.burned,
+	(SELECT COUNT(*) FROM stars WHERE stars.snippet_id = s.id)`
+
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+)
+
 type Snippet struct {
-	ID      int
-	Title   string
-	Content string
-	Created time.Time
-	Expires time.Time
+	ID               int
+	UserID           int
+	UserName         string
+	ForkedFrom       int
+	ForkedFromPublic bool
+	Forks            int
+	Stars            int
+	Title            string
+	Content          string
+	Filename         string
+	Language         string
+	Visibility       string
+	Slug             string
+	HashedPassword   []byte
+	BurnAfterReading bool
+	Created          time.Time
+	Expires          time.Time
+	Deleted          time.Time
+	Burned           time.Time
+	Tags             []string
+	Files            []File
+}
+
+// Ref returns the path segment a snippet is reached through. Only public
+// snippets are addressed by their sequential id; everything else goes through
+// its random slug so it can't be found by enumeration.
+func (snip Snippet) Ref() string {
+	if snip.Visibility == VisibilityPublic || snip.Slug == "" {
+		return strconv.Itoa(snip.ID)
+	}
+
+	return snip.Slug
+}
+
+// Protected reports whether the snippet has an access password.
+func (snip Snippet) Protected() bool {
+	return len(snip.HashedPassword) > 0
+}
+
+// Authenticate checks password against the snippet's access password and
+// returns ErrInvalidCredentials if it doesn't match.
+func (snip Snippet) Authenticate(password string) error {
+	err := bcrypt.CompareHashAndPassword(snip.HashedPassword, []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrInvalidCredentials
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SnippetInput holds the user-editable fields of a snippet. Filename,
+// Language and Content describe its first file and Files any others. A zero
+// Expires means the snippet never expires. A blank Password
+// leaves the access password as it is unless RemovePassword is set.
+// BurnAfterReading can only be chosen when the snippet is created.
+type SnippetInput struct {
+	Title            string
+	Content          string
+	Filename         string
+	Language         string
+	Visibility       string
+	Password         string
+	RemovePassword   bool
+	BurnAfterReading bool
+	Expires          time.Time
+	Tags             []string
+	Files            []File
+}
+
+// Revision holds a snippet's title and files as they were before the edit
+// made by UserID at Created. Filename, Language and Content are its first
+// file and Files any others, as on Snippet. Revisions only loads Files when
+// asked for a single revision by GetRevision.
+type Revision struct {
+	ID        int
+	SnippetID int
+	UserID    int
+	UserName  string
+	Title     string
+	Content   string
+	Filename  string
+	Language  string
+	Files     []File
+	Created   time.Time
 }
 
 type SnippetModel struct {
 	DB *sql.DB
 }
 
-func (model *SnippetModel) Insert(title string, content string, expires int) (int, error) {
-	statement := `INSERT INTO snippets (title, content, created, expires)
-	VALUES(?, ?, UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL ? DAY))`
+func (model *SnippetModel) Insert(userID int, input SnippetInput) (int, error) {
+	tx, err := model.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	slug, err := newSlug()
+	if err != nil {
+		return 0, err
+	}
+
+	hashedPassword, err := hashSnippetPassword(input.Password)
+	if err != nil {
+		return 0, err
+	}
+
+	statement := `INSERT INTO snippets (user_id, title, content, filename, language, visibility, slug, hashed_password,
+	burn_after_reading, created, expires)
+	VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, UTC_TIMESTAMP(), ?)`
 
-	result, err := model.DB.Exec(statement, title, content, expires)
+	result, err := tx.Exec(statement, userID, input.Title, input.Content, input.Filename, input.Language, input.Visibility, slug,
+		hashedPassword, input.BurnAfterReading, expiry(input.Expires))
 	if err != nil {
 		return 0, err
 	}
@@ -38,18 +179,38 @@ func (model *SnippetModel) Insert(title string, content string, expires int) (in
 		return 0, err
 	}
 
+	err = setTags(tx, int(id), input.Tags)
+	if err != nil {
+		return 0, err
+	}
+
+	err = setFiles(tx, int(id), input.Files)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
 	return int(id), nil
 }
 
 func (model *SnippetModel) Get(id int) (Snippet, error) {
-	statement := `SELECT id, title, content, created, expires FROM snippets
-	WHERE expires > UTC_TIMESTAMP() AND id = ?`
+	return model.get("s.id = ?", id)
+}
 
-	row := model.DB.QueryRow(statement, id)
+func (model *SnippetModel) GetBySlug(slug string) (Snippet, error) {
+	return model.get("s.slug = ?", slug)
+}
 
-	var snip Snippet
+func (model *SnippetModel) get(condition string, arg any) (Snippet, error) {
+	statement := `SELECT ` + snippetColumns + ` FROM snippets s
+	INNER JOIN users u ON u.id = s.user_id
+	WHERE (s.expires IS NULL OR s.expires > UTC_TIMESTAMP()) AND ` + condition
 
-	err := row.Scan(&snip.ID, &snip.Title, &snip.Content, &snip.Created, &snip.Expires)
+	snip, err := scanSnippet(model.DB.QueryRow(statement, arg))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return Snippet{}, ErrNoRecord
@@ -58,36 +219,484 @@ func (model *SnippetModel) Get(id int) (Snippet, error) {
 		}
 	}
 
+	// Burned and trashed snippets are still returned so callers can check
+	// who owns them before revealing that they existed.
+	if !snip.Burned.IsZero() {
+		return snip, ErrBurned
+	}
+
+	if !snip.Deleted.IsZero() {
+		return snip, ErrDeleted
+	}
+
+	snip.Tags, err = tagsOf(model.DB, snip.ID)
+	if err != nil {
+		return Snippet{}, err
+	}
+
+	snip.Files, err = filesOf(model.DB, snip.ID)
+	if err != nil {
+		return Snippet{}, err
+	}
+
+	statement = `SELECT COUNT(*) FROM snippets WHERE deleted IS NULL AND forked_from = ?`
+
+	err = model.DB.QueryRow(statement, snip.ID).Scan(&snip.Forks)
+	if err != nil {
+		return Snippet{}, err
+	}
+
+	// The original is only linked to when anyone can open it by its id.
+	if snip.ForkedFrom != 0 {
+		statement = `SELECT EXISTS(SELECT 1 FROM snippets WHERE id = ? AND visibility = 'public' AND burn_after_reading = FALSE
+		AND deleted IS NULL AND (expires IS NULL OR expires > UTC_TIMESTAMP()))`
+
+		err = model.DB.QueryRow(statement, snip.ForkedFrom).Scan(&snip.ForkedFromPublic)
+		if err != nil {
+			return Snippet{}, err
+		}
+	}
+
 	return snip, nil
 }
 
-func (model *SnippetModel) Latest() ([]Snippet, error) {
-	statement := `SELECT id, title, content, created, expires FROM snippets
-	WHERE expires > UTC_TIMESTAMP() ORDER BY id DESC LIMIT 10`
+// Fork copies the title, files, visibility, access password and expiry of
+// snippet id into a new snippet owned by userID and returns the new snippet's
+// id. Keeping the password stops a fork from publishing a protected snippet.
+func (model *SnippetModel) Fork(id int, userID int) (int, error) {
+	tx, err := model.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	slug, err := newSlug()
+	if err != nil {
+		return 0, err
+	}
+
+	statement := `INSERT INTO snippets (user_id, forked_from, title, content, filename, language, visibility, slug, hashed_password, created, expires)
+	SELECT ?, id, title, content, filename, language, visibility, ?, hashed_password, UTC_TIMESTAMP(), expires FROM snippets
+	WHERE (expires IS NULL OR expires > UTC_TIMESTAMP()) AND deleted IS NULL AND id = ?`
+
+	result, err := tx.Exec(statement, userID, slug, id)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rows == 0 {
+		return 0, ErrNoRecord
+	}
+
+	forkID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	statement = `INSERT INTO snippet_files (snippet_id, position, name, language, content)
+	SELECT ?, position, name, language, content FROM snippet_files WHERE snippet_id = ?`
+
+	_, err = tx.Exec(statement, forkID, id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(forkID), nil
+}
+
+func (model *SnippetModel) Update(id int, userID int, input SnippetInput) error {
+	tx, err := model.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var old Revision
+
+	statement := `SELECT title, content, filename, language FROM snippets
+	WHERE (expires IS NULL OR expires > UTC_TIMESTAMP()) AND deleted IS NULL AND id = ? FOR UPDATE`
+
+	err = tx.QueryRow(statement, id).Scan(&old.Title, &old.Content, &old.Filename, &old.Language)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoRecord
+		}
+		return err
+	}
+
+	old.Files, err = filesOf(tx, id)
+	if err != nil {
+		return err
+	}
+
+	// Any change to the title or files, including their names and languages,
+	// keeps the old version.
+	changed := old.Title != input.Title || old.Content != input.Content || old.Filename != input.Filename ||
+		old.Language != input.Language || !slices.Equal(old.Files, input.Files)
+
+	if changed {
+		statement = `INSERT INTO snippet_revisions (snippet_id, user_id, title, content, filename, language, created)
+		VALUES(?, ?, ?, ?, ?, ?, UTC_TIMESTAMP())`
+
+		result, err := tx.Exec(statement, id, userID, old.Title, old.Content, old.Filename, old.Language)
+		if err != nil {
+			return err
+		}
+
+		revisionID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		err = setRevisionFiles(tx, revisionID, old.Files)
+		if err != nil {
+			return err
+		}
+	}
 
-	rows, err := model.DB.Query(statement)
+	slug, err := newSlug()
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := hashSnippetPassword(input.Password)
+	if err != nil {
+		return err
+	}
+
+	changePassword := input.Password != "" || input.RemovePassword
+
+	statement = `UPDATE snippets SET title = ?, content = ?, filename = ?, language = ?, visibility = ?, slug = COALESCE(slug, ?),
+	hashed_password = IF(?, ?, hashed_password), expires = ?
+	WHERE id = ?`
+
+	_, err = tx.Exec(statement, input.Title, input.Content, input.Filename, input.Language, input.Visibility, slug,
+		changePassword, hashedPassword, expiry(input.Expires), id)
+	if err != nil {
+		return err
+	}
+
+	err = setTags(tx, id, input.Tags)
+	if err != nil {
+		return err
+	}
+
+	err = setFiles(tx, id, input.Files)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (model *SnippetModel) Revisions(id int) ([]Revision, error) {
+	statement := `SELECT r.id, r.snippet_id, r.user_id, u.name, r.title, r.content, r.filename, r.language, r.created FROM snippet_revisions r
+	INNER JOIN users u ON u.id = r.user_id
+	WHERE r.snippet_id = ? ORDER BY r.id DESC`
+
+	rows, err := model.DB.Query(statement, id)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
 
-	var snippets []Snippet
+	var revisions []Revision
 
 	for rows.Next() {
-		var snip Snippet
+		var revision Revision
 
-		err = rows.Scan(&snip.ID, &snip.Title, &snip.Content, &snip.Created, &snip.Expires)
+		err = rows.Scan(&revision.ID, &revision.SnippetID, &revision.UserID, &revision.UserName, &revision.Title, &revision.Content,
+			&revision.Filename, &revision.Language, &revision.Created)
 		if err != nil {
 			return nil, err
 		}
 
-		snippets = append(snippets, snip)
+		revisions = append(revisions, revision)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
 
+	return revisions, nil
+}
+
+func (model *SnippetModel) GetRevision(id int, revisionID int) (Revision, error) {
+	statement := `SELECT r.id, r.snippet_id, r.user_id, u.name, r.title, r.content, r.filename, r.language, r.created FROM snippet_revisions r
+	INNER JOIN users u ON u.id = r.user_id
+	WHERE r.snippet_id = ? AND r.id = ?`
+
+	var revision Revision
+
+	err := model.DB.QueryRow(statement, id, revisionID).Scan(&revision.ID, &revision.SnippetID, &revision.UserID, &revision.UserName, &revision.Title, &revision.Content,
+		&revision.Filename, &revision.Language, &revision.Created)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Revision{}, ErrNoRecord
+		} else {
+			return Revision{}, err
+		}
+	}
+
+	revision.Files, err = revisionFilesOf(model.DB, revision.ID)
+	if err != nil {
+		return Revision{}, err
+	}
+
+	return revision, nil
+}
+
+func (model *SnippetModel) Latest(cursor Cursor) (SnippetPage, error) {
+	statement := `SELECT ` + snippetColumns + ` FROM snippets s
+	INNER JOIN users u ON u.id = s.user_id
+	WHERE (s.expires IS NULL OR s.expires > UTC_TIMESTAMP()) AND s.deleted IS NULL AND s.visibility = 'public' AND s.burn_after_reading = FALSE`
+
+	return model.page(cursor, statement)
+}
+
+func (model *SnippetModel) ByUser(userID int, cursor Cursor) (SnippetPage, error) {
+	statement := `SELECT ` + snippetColumns + ` FROM snippets s
+	INNER JOIN users u ON u.id = s.user_id
+	WHERE (s.expires IS NULL OR s.expires > UTC_TIMESTAMP()) AND s.deleted IS NULL AND s.user_id = ?`
+
+	return model.page(cursor, statement, userID)
+}
+
+func (model *SnippetModel) page(cursor Cursor, statement string, args ...any) (SnippetPage, error) {
+	clause, clauseArgs := cursor.clause()
+
+	rows, err := model.DB.Query(statement+clause, append(args, clauseArgs...)...)
+	if err != nil {
+		return SnippetPage{}, err
+	}
+
+	snippets, err := scanSnippets(rows)
+	if err != nil {
+		return SnippetPage{}, err
+	}
+
+	return cursor.page(snippets), nil
+}
+
+func (model *SnippetModel) Delete(id int) error {
+	statement := `UPDATE snippets SET deleted = UTC_TIMESTAMP() WHERE deleted IS NULL AND id = ?`
+
+	_, err := model.DB.Exec(statement, id)
+	return err
+}
+
+// Burn returns a burn after reading snippet and destroys it in the same
+// transaction, so only one caller ever sees its content. Everyone after that
+// gets ErrBurned. The emptied row is kept in the trash, hidden from its owner,
+// until it is purged so later visitors can be told what happened to it.
+func (model *SnippetModel) Burn(id int) (Snippet, error) {
+	tx, err := model.DB.Begin()
+	if err != nil {
+		return Snippet{}, err
+	}
+
+	defer tx.Rollback()
+
+	statement := `SELECT ` + snippetColumns + ` FROM snippets s
+	INNER JOIN users u ON u.id = s.user_id
+	WHERE (s.expires IS NULL OR s.expires > UTC_TIMESTAMP()) AND s.id = ? FOR UPDATE`
+
+	snip, err := scanSnippet(tx.QueryRow(statement, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Snippet{}, ErrNoRecord
+		} else {
+			return Snippet{}, err
+		}
+	}
+
+	if !snip.Burned.IsZero() {
+		return Snippet{}, ErrBurned
+	}
+
+	if !snip.Deleted.IsZero() {
+		return Snippet{}, ErrDeleted
+	}
+
+	snip.Tags, err = tagsOf(tx, id)
+	if err != nil {
+		return Snippet{}, err
+	}
+
+	snip.Files, err = filesOf(tx, id)
+	if err != nil {
+		return Snippet{}, err
+	}
+
+	statements := []string{
+		`UPDATE snippets SET title = '', content = '', filename = '', deleted = UTC_TIMESTAMP(), burned = UTC_TIMESTAMP() WHERE id = ?`,
+		`DELETE FROM snippet_revisions WHERE snippet_id = ?`,
+		`DELETE FROM snippet_tags WHERE snippet_id = ?`,
+		`DELETE FROM snippet_files WHERE snippet_id = ?`,
+	}
+
+	for _, statement := range statements {
+		_, err = tx.Exec(statement, id)
+		if err != nil {
+			return Snippet{}, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return Snippet{}, err
+	}
+
+	return snip, nil
+}
+
+func (model *SnippetModel) Restore(id int, userID int) error {
+	statement := `UPDATE snippets SET deleted = NULL
+	WHERE deleted > DATE_SUB(UTC_TIMESTAMP(), INTERVAL ? DAY) AND burned IS NULL AND id = ? AND user_id = ?`
+
+	result, err := model.DB.Exec(statement, TrashRetentionDays, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+func (model *SnippetModel) Trash(userID int) ([]Snippet, error) {
+	statement := `SELECT ` + snippetColumns + ` FROM snippets s
+	INNER JOIN users u ON u.id = s.user_id
+	WHERE s.deleted > DATE_SUB(UTC_TIMESTAMP(), INTERVAL ? DAY) AND s.burned IS NULL AND s.user_id = ?
+	ORDER BY s.deleted DESC`
+
+	rows, err := model.DB.Query(statement, TrashRetentionDays, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSnippets(rows)
+}
+
+// PurgeTrash permanently deletes up to limit snippets that have been in the
+// trash for longer than TrashRetentionDays.
+func (model *SnippetModel) PurgeTrash(limit int) (int64, error) {
+	statement := `DELETE FROM snippets WHERE deleted <= DATE_SUB(UTC_TIMESTAMP(), INTERVAL ? DAY) LIMIT ?`
+
+	result, err := model.DB.Exec(statement, TrashRetentionDays, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteExpired permanently deletes up to limit snippets that have expired.
+func (model *SnippetModel) DeleteExpired(limit int) (int64, error) {
+	statement := `DELETE FROM snippets WHERE expires <= UTC_TIMESTAMP() LIMIT ?`
+
+	result, err := model.DB.Exec(statement, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSnippet reads the columns listed in snippetColumns followed by any
+// extra columns the query selected into extra.
+func scanSnippet(row rowScanner, extra ...any) (Snippet, error) {
+	var snip Snippet
+	var expires, deleted, burned sql.NullTime
+
+	dest := []any{&snip.ID, &snip.UserID, &snip.UserName, &snip.ForkedFrom, &snip.Title, &snip.Content, &snip.Filename, &snip.Language, &snip.Visibility, &snip.Slug,
+		&snip.HashedPassword, &snip.BurnAfterReading, &snip.Created, &expires, &deleted, &burned, &snip.Stars}
+
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return Snippet{}, err
+	}
+
+	snip.Expires = expires.Time
+	snip.Deleted = deleted.Time
+	snip.Burned = burned.Time
+
+	return snip, nil
+}
+
+func scanSnippets(rows *sql.Rows) ([]Snippet, error) {
+	defer rows.Close()
+
+	var snippets []Snippet
+
+	for rows.Next() {
+		snip, err := scanSnippet(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		snippets = append(snippets, snip)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return snippets, nil
 }
+
+// newSlug returns 128 random bits encoded as a 22 character URL-safe string.
+func newSlug() (string, error) {
+	b := make([]byte, 16)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// expiry maps the zero time, meaning never, to NULL.
+func expiry(expires time.Time) sql.NullTime {
+	return sql.NullTime{Time: expires.UTC(), Valid: !expires.IsZero()}
+}
+
+// hashSnippetPassword returns the bcrypt hash of password, or NULL if the
+// password is blank.
+func hashSnippetPassword(password string) (sql.NullString, error) {
+	if password == "" {
+		return sql.NullString{}, nil
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(hashedPassword), Valid: true}, nil
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+func TestSnippetModelUpdateRevisions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+	model := SnippetModel{db}
+
+	input := SnippetInput{
+		Title:      "An old silent pond",
+		Content:    "An old silent pond...",
+		Language:   "plaintext",
+		Visibility: VisibilityPublic,
+		Files:      []File{{Name: "notes.txt", Language: "plaintext", Content: "A frog jumps in."}},
+	}
+
+	// Adding a file is a change even though the first file is the same.
+	err := model.Update(1, 1, input)
+	assert.NilError(t, err)
+
+	revisions, err := model.Revisions(1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(revisions), 1)
+
+	// Saving without changes keeps no revision.
+	err = model.Update(1, 1, input)
+	assert.NilError(t, err)
+
+	revisions, err = model.Revisions(1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(revisions), 1)
+
+	input.Files[0].Language = "markdown"
+
+	err = model.Update(1, 1, input)
+	assert.NilError(t, err)
+
+	revisions, err = model.Revisions(1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(revisions), 2)
+
+	revision, err := model.GetRevision(1, revisions[0].ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(revision.Files), 1)
+	assert.Equal(t, revision.Files[0].Language, "plaintext")
+}
+
+func TestSnippetModelBurn(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+	model := SnippetModel{db}
+
+	id, err := model.Insert(1, SnippetInput{
+		Title:            "One-time token",
+		Content:          "token: 5f4dcc3b",
+		Language:         "yaml",
+		Visibility:       VisibilityPublic,
+		BurnAfterReading: true,
+		Files:            []File{{Name: "extra.txt", Language: "plaintext", Content: "more"}},
+	})
+	assert.NilError(t, err)
+
+	t.Run("Not listed", func(t *testing.T) {
+		page, err := model.Latest(Cursor{})
+		assert.NilError(t, err)
+
+		for _, snip := range page.Snippets {
+			if snip.ID == id {
+				t.Errorf("burn after reading snippet %d listed in Latest", id)
+			}
+		}
+	})
+
+	t.Run("Only once", func(t *testing.T) {
+		// However many readers race for the snippet, only one gets it.
+		const readers = 5
+
+		var (
+			wg     sync.WaitGroup
+			mu     sync.Mutex
+			burned []Snippet
+			errs   []error
+		)
+
+		for range readers {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				snip, err := model.Burn(id)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					burned = append(burned, snip)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, len(burned), 1)
+		assert.Equal(t, burned[0].Content, "token: 5f4dcc3b")
+		assert.Equal(t, len(burned[0].Files), 1)
+
+		for _, err := range errs {
+			assert.Equal(t, errors.Is(err, ErrBurned), true)
+		}
+	})
+
+	t.Run("Gone afterwards", func(t *testing.T) {
+		snip, err := model.Get(id)
+		assert.Equal(t, errors.Is(err, ErrBurned), true)
+		assert.Equal(t, snip.Content, "")
+
+		err = model.Restore(id, 1)
+		assert.Equal(t, errors.Is(err, ErrNoRecord), true)
+	})
+}
+
+func TestSnippetModelFork(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+	model := SnippetModel{db}
+
+	id, err := model.Insert(1, SnippetInput{
+		Title:      "Staging credentials",
+		Content:    "DB_PASSWORD=hunter2",
+		Language:   "ini",
+		Visibility: VisibilityPublic,
+		Password:   "open sesame",
+		Files:      []File{{Name: "notes.txt", Language: "plaintext", Content: "Rotate monthly"}},
+	})
+	assert.NilError(t, err)
+
+	forkID, err := model.Fork(id, 1)
+	assert.NilError(t, err)
+
+	fork, err := model.Get(forkID)
+	assert.NilError(t, err)
+
+	assert.Equal(t, fork.ForkedFrom, id)
+	assert.Equal(t, fork.Content, "DB_PASSWORD=hunter2")
+	assert.Equal(t, fork.Protected(), true)
+	assert.NilError(t, fork.Authenticate("open sesame"))
+	assert.Equal(t, len(fork.Files), 1)
+
+	_, err = model.Fork(999, 1)
+	assert.Equal(t, errors.Is(err, ErrNoRecord), true)
+}
+
+func TestSnippetModelRestore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+	model := SnippetModel{db}
+
+	err := model.Delete(1)
+	assert.NilError(t, err)
+
+	_, err = model.Get(1)
+	assert.Equal(t, errors.Is(err, ErrDeleted), true)
+
+	err = model.Restore(1, 2)
+	assert.Equal(t, errors.Is(err, ErrNoRecord), true)
+
+	err = model.Restore(1, 1)
+	assert.NilError(t, err)
+
+	_, err = model.Get(1)
+	assert.NilError(t, err)
+}
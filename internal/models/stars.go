@@ -0,0 +1,41 @@
+package models
+
+// Star bookmarks snippet id for userID. Starring a snippet twice is not an
+// error.
+func (model *SnippetModel) Star(id int, userID int) error {
+	statement := `INSERT IGNORE INTO stars (user_id, snippet_id, created) VALUES(?, ?, UTC_TIMESTAMP())`
+
+	_, err := model.DB.Exec(statement, userID, id)
+
+	return err
+}
+
+func (model *SnippetModel) Unstar(id int, userID int) error {
+	statement := `DELETE FROM stars WHERE user_id = ? AND snippet_id = ?`
+
+	_, err := model.DB.Exec(statement, userID, id)
+
+	return err
+}
+
+func (model *SnippetModel) IsStarred(id int, userID int) (bool, error) {
+	var starred bool
+
+	statement := `SELECT EXISTS(SELECT true FROM stars WHERE user_id = ? AND snippet_id = ?)`
+
+	err := model.DB.QueryRow(statement, userID, id).Scan(&starred)
+
+	return starred, err
+}
+
+// Starred returns the snippets userID has starred that they can still see.
+// Snippets made private by their owner since they were starred drop out.
+func (model *SnippetModel) Starred(userID int, cursor Cursor) (SnippetPage, error) {
+	statement := `SELECT ` + snippetColumns + ` FROM snippets s
+	INNER JOIN users u ON u.id = s.user_id
+	INNER JOIN stars sr ON sr.snippet_id = s.id
+	WHERE (s.expires IS NULL OR s.expires > UTC_TIMESTAMP()) AND s.deleted IS NULL
+	AND (s.visibility <> 'private' OR s.user_id = sr.user_id) AND sr.user_id = ?`
+
+	return model.page(cursor, statement, userID)
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// NormalizeTags splits a comma-separated list of tags, lower-cases and trims
+// each one and drops blanks and duplicates while keeping the original order.
+func NormalizeTags(raw string) []string {
+	var tags []string
+	seen := map[string]bool{}
+
+	for _, tag := range strings.Split(raw, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+func (model *SnippetModel) Tagged(tag string, cursor Cursor) (SnippetPage, error) {
+	statement := `SELECT ` + snippetColumns + ` FROM snippets s
+	INNER JOIN users u ON u.id = s.user_id
+	INNER JOIN snippet_tags st ON st.snippet_id = s.id
+	INNER JOIN tags t ON t.id = st.tag_id
+	WHERE (s.expires IS NULL OR s.expires > UTC_TIMESTAMP()) AND s.deleted IS NULL AND s.visibility = 'public' AND s.burn_after_reading = FALSE AND t.name = ?`
+
+	return model.page(cursor, statement, strings.ToLower(strings.TrimSpace(tag)))
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func tagsOf(db querier, id int) ([]string, error) {
+	statement := `SELECT t.name FROM tags t
+	INNER JOIN snippet_tags st ON st.tag_id = t.id
+	WHERE st.snippet_id = ? ORDER BY t.name`
+
+	rows, err := db.Query(statement, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tags []string
+
+	for rows.Next() {
+		var tag string
+
+		err = rows.Scan(&tag)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// setTags replaces the tags of snippet id, creating any tags that don't exist
+// yet.
+func setTags(tx *sql.Tx, id int, tags []string) error {
+	_, err := tx.Exec(`DELETE FROM snippet_tags WHERE snippet_id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range NormalizeTags(strings.Join(tags, ",")) {
+		result, err := tx.Exec(`INSERT INTO tags (name) VALUES(?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)`, tag)
+		if err != nil {
+			return err
+		}
+
+		tagID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`INSERT INTO snippet_tags (snippet_id, tag_id) VALUES(?, ?)`, id, tagID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
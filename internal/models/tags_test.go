@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "Empty",
+			raw:  "",
+			want: "",
+		},
+		{
+			name: "Lower case and trimmed",
+			raw:  " Go ,  Kubernetes",
+			want: "go|kubernetes",
+		},
+		{
+			name: "Duplicates",
+			raw:  "go,GO, go ,sql",
+			want: "go|sql",
+		},
+		{
+			name: "Blanks",
+			raw:  ",,go,, ,",
+			want: "go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, strings.Join(NormalizeTags(tt.raw), "|"), tt.want)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type ViewModelInterface interface {
+	Add(counts []ViewCount) error
+	Stats(snippetID int, days int) (ViewStats, error)
+}
+
+// ViewCount is what was seen of one snippet on one day since the last time
+// counts were added. Referrers maps referring hosts, or "" for none, to the
+// number of views they sent.
+type ViewCount struct {
+	SnippetID int
+	Day       time.Time
+	Views     int
+	Uniques   int
+	Referrers map[string]int
+}
+
+type DailyViews struct {
+	Day     time.Time
+	Views   int
+	Uniques int
+}
+
+type ReferrerCount struct {
+	Referrer string
+	Views    int
+}
+
+// ViewStats summarises the views of a snippet. Views and Uniques cover all
+// time, with Uniques being the sum of each day's unique viewers. Days has one
+// entry per day of the requested period, oldest first, and Referrers the top
+// referrers over the same period.
+type ViewStats struct {
+	Views     int
+	Uniques   int
+	Days      []DailyViews
+	Referrers []ReferrerCount
+}
+
+// MaxReferrers is how many referrers Stats returns.
+const MaxReferrers = 10
+
+type ViewModel struct {
+	DB *sql.DB
+}
+
+// Add adds counts to the stored totals in a single transaction. Counts for
+// snippets that have since been deleted for good are dropped.
+func (model *ViewModel) Add(counts []ViewCount) error {
+	tx, err := model.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	// Selecting from snippets inserts nothing for a snippet the reaper has
+	// removed since it was viewed, where a plain insert would fail on the
+	// foreign key and lose every other snippet's counts with it.
+	for _, count := range counts {
+		statement := `INSERT INTO snippet_views (snippet_id, day, views, uniques)
+		SELECT id, ?, ?, ? FROM snippets WHERE id = ?
+		ON DUPLICATE KEY UPDATE views = views + VALUES(views), uniques = uniques + VALUES(uniques)`
+
+		_, err = tx.Exec(statement, count.Day, count.Views, count.Uniques, count.SnippetID)
+		if err != nil {
+			return err
+		}
+
+		for referrer, views := range count.Referrers {
+			statement := `INSERT INTO snippet_referrers (snippet_id, day, referrer, views)
+			SELECT id, ?, ?, ? FROM snippets WHERE id = ?
+			ON DUPLICATE KEY UPDATE views = views + VALUES(views)`
+
+			_, err = tx.Exec(statement, count.Day, referrer, views, count.SnippetID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Stats returns the view statistics of snippetID over the last days days,
+// including today.
+func (model *ViewModel) Stats(snippetID int, days int) (ViewStats, error) {
+	var stats ViewStats
+
+	statement := `SELECT COALESCE(SUM(views), 0), COALESCE(SUM(uniques), 0) FROM snippet_views WHERE snippet_id = ?`
+
+	err := model.DB.QueryRow(statement, snippetID).Scan(&stats.Views, &stats.Uniques)
+	if err != nil {
+		return ViewStats{}, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	first := today.AddDate(0, 0, 1-days)
+
+	statement = `SELECT day, views, uniques FROM snippet_views WHERE snippet_id = ? AND day >= ? ORDER BY day`
+
+	rows, err := model.DB.Query(statement, snippetID, first)
+	if err != nil {
+		return ViewStats{}, err
+	}
+
+	defer rows.Close()
+
+	recorded := map[time.Time]DailyViews{}
+
+	for rows.Next() {
+		var day DailyViews
+
+		err = rows.Scan(&day.Day, &day.Views, &day.Uniques)
+		if err != nil {
+			return ViewStats{}, err
+		}
+
+		recorded[day.Day.UTC()] = day
+	}
+
+	if err = rows.Err(); err != nil {
+		return ViewStats{}, err
+	}
+
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		views, ok := recorded[day]
+		if !ok {
+			views = DailyViews{Day: day}
+		}
+
+		stats.Days = append(stats.Days, views)
+	}
+
+	statement = `SELECT referrer, SUM(views) AS total FROM snippet_referrers
+	WHERE snippet_id = ? AND day >= ?
+	GROUP BY referrer ORDER BY total DESC, referrer LIMIT ?`
+
+	rows, err = model.DB.Query(statement, snippetID, first, MaxReferrers)
+	if err != nil {
+		return ViewStats{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var referrer ReferrerCount
+
+		err = rows.Scan(&referrer.Referrer, &referrer.Views)
+		if err != nil {
+			return ViewStats{}, err
+		}
+
+		stats.Referrers = append(stats.Referrers, referrer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return ViewStats{}, err
+	}
+
+	return stats, nil
+}
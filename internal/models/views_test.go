@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tyler-Meador/snippetbox/internal/assert"
+)
+
+func TestViewModelAdd(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+	model := ViewModel{db}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	// Snippet 2 doesn't exist, as if it had been purged before the flush.
+	err := model.Add([]ViewCount{
+		{SnippetID: 1, Day: today, Views: 3, Uniques: 2, Referrers: map[string]int{"example.com": 3}},
+		{SnippetID: 2, Day: today, Views: 5, Uniques: 5, Referrers: map[string]int{"": 5}},
+	})
+	assert.NilError(t, err)
+
+	stats, err := model.Stats(1, 1)
+	assert.NilError(t, err)
+
+	assert.Equal(t, stats.Views, 3)
+	assert.Equal(t, stats.Uniques, 2)
+	assert.Equal(t, len(stats.Referrers), 1)
+
+	stats, err = model.Stats(2, 1)
+	assert.NilError(t, err)
+
+	assert.Equal(t, stats.Views, 0)
+}
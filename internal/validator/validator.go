@@ -14,6 +14,10 @@ type Validator struct {
 
 var EmailRX = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
 
+var TagRX = regexp.MustCompile(`^[a-z0-9][a-z0-9.+#_-]*$`)
+
+var FileNameRX = regexp.MustCompile(`^[A-Za-z0-9._-]*[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
 func MinChars(value string, n int) bool {
 	return utf8.RuneCountInString(value) >= n
 }
@@ -5,4 +5,83 @@ for (var i = 0; i < navLinks.length; i++) {
 		link.classList.add("live");
 		break;
 	}
-}
\ No newline at end of file
+}
+
+// Mark the lines named by a fragment such as #L3 or #L3-L9, switching a
+// Markdown file to its source if they're in one.
+var lineAnchor = /^#((?:f\d+-)?L)(\d+)(?:-L(\d+))?$/;
+
+function selectLines(scroll) {
+	var selected = document.querySelectorAll(".chroma .line.selected");
+	for (var i = 0; i < selected.length; i++) {
+		selected[i].classList.remove("selected");
+	}
+
+	var match = lineAnchor.exec(window.location.hash);
+	if (!match) {
+		return;
+	}
+
+	var start = parseInt(match[2], 10);
+	var end = match[3] ? parseInt(match[3], 10) : start;
+	var first = null;
+
+	for (var n = Math.min(start, end); n <= Math.max(start, end); n++) {
+		var number = document.getElementById(match[1] + n);
+		if (!number) {
+			break;
+		}
+
+		number.parentNode.classList.add("selected");
+		first = first || number;
+	}
+
+	if (!first) {
+		return;
+	}
+
+	var toggle = first.closest(".markdown-toggle");
+	if (toggle) {
+		toggle.querySelector("input.source").checked = true;
+	}
+
+	if (scroll) {
+		first.scrollIntoView();
+	}
+}
+
+// Shift-clicking a line number selects the lines from the one clicked last.
+var lastLine = null;
+
+document.addEventListener("click", function (event) {
+	var link = event.target.closest && event.target.closest(".chroma a.lnlinks");
+	if (!link) {
+		return;
+	}
+
+	var match = lineAnchor.exec(link.getAttribute("href"));
+	if (!match) {
+		return;
+	}
+
+	var number = parseInt(match[2], 10);
+
+	if (event.shiftKey && lastLine && lastLine.prefix == match[1]) {
+		event.preventDefault();
+
+		var start = Math.min(lastLine.number, number);
+		var end = Math.max(lastLine.number, number);
+
+		history.replaceState(null, "", "#" + match[1] + start + "-L" + end);
+		selectLines(false);
+		return;
+	}
+
+	lastLine = {prefix: match[1], number: number};
+});
+
+window.addEventListener("hashchange", function () {
+	selectLines(true);
+});
+
+selectLines(true);